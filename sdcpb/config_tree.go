@@ -0,0 +1,173 @@
+package sdcpb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ConfigTree is an in-memory configuration store that applies gNMI Set semantics.
+// It holds the leaf values indexed by path and can be used as a reference model or a local stand-in target.
+type ConfigTree struct {
+	leaves *PathTrie[*TypedValue]
+	// lookup is optional and used to decompose json values into individual leafs
+	lookup    SchemaLookupFunc
+	timestamp int64
+}
+
+// NewConfigTree returns an empty ConfigTree. The lookup is optional, it is required to apply
+// updates and replaces that carry json or json_ietf values on container paths.
+func NewConfigTree(lookup SchemaLookupFunc) *ConfigTree {
+	return &ConfigTree{
+		leaves: NewPathTrie[*TypedValue](),
+		lookup: lookup,
+	}
+}
+
+// Set applies the deletes, replaces and updates of the request, see Apply.
+func (c *ConfigTree) Set(req *SetDataRequest) (*SetDataResponse, error) {
+	results, err := c.Apply(req.GetDelete(), req.GetReplace(), req.GetUpdate())
+	if err != nil {
+		return nil, err
+	}
+	return &SetDataResponse{
+		Response:  results,
+		Timestamp: c.timestamp,
+	}, nil
+}
+
+// Apply modifies the tree as a single transaction, following the gNMI Set semantics:
+// all deletes are processed first, then the replaces, which clear the whole subtree before setting
+// the new values, and finally the updates, which merge the new values with the existing ones.
+// If any of the operations fails, the tree is left unchanged.
+func (c *ConfigTree) Apply(deletes []*Path, replaces []*Update, updates []*Update) ([]*UpdateResult, error) {
+	leaves := NewPathTrie[*TypedValue]()
+	for p, v := range c.leaves.All() {
+		leaves.Insert(p, v)
+	}
+
+	results := make([]*UpdateResult, 0, len(deletes)+len(replaces)+len(updates))
+	for _, p := range deletes {
+		deleteSubtree(leaves, p)
+		results = append(results, &UpdateResult{Path: p, Op: UpdateResult_DELETE})
+	}
+	for _, u := range replaces {
+		deleteSubtree(leaves, u.GetPath())
+		if err := c.merge(leaves, u); err != nil {
+			return nil, fmt.Errorf("replace %s: %w", u.GetPath().ToXPath(false), err)
+		}
+		results = append(results, &UpdateResult{Path: u.GetPath(), Op: UpdateResult_REPLACE})
+	}
+	for _, u := range updates {
+		if err := c.merge(leaves, u); err != nil {
+			return nil, fmt.Errorf("update %s: %w", u.GetPath().ToXPath(false), err)
+		}
+		results = append(results, &UpdateResult{Path: u.GetPath(), Op: UpdateResult_UPDATE})
+	}
+
+	c.leaves = leaves
+	c.timestamp = time.Now().UnixNano()
+	return results, nil
+}
+
+// deleteSubtree removes all leafs at or below the given path.
+func deleteSubtree(leaves *PathTrie[*TypedValue], p *Path) {
+	remove := []*Path{}
+	for lp := range leaves.Descendants(p) {
+		remove = append(remove, lp)
+	}
+	for _, lp := range remove {
+		leaves.Delete(lp)
+	}
+}
+
+// merge sets the leaf values carried by the update, decomposing json object values into their leafs.
+func (c *ConfigTree) merge(leaves *PathTrie[*TypedValue], u *Update) error {
+	updates, err := c.decompose(u)
+	if err != nil {
+		return err
+	}
+	for _, leaf := range updates {
+		if err := setLeaf(leaves, leaf.GetPath(), leaf.GetValue()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decompose splits json object values into the updates of their individual leafs.
+// Scalar json values are stored as is, as long as the path does not reference a container.
+func (c *ConfigTree) decompose(u *Update) ([]*Update, error) {
+	raw := u.GetValue().GetJsonVal()
+	if raw == nil {
+		raw = u.GetValue().GetJsonIetfVal()
+	}
+	if raw == nil {
+		return []*Update{u}, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("invalid json value: %w", err)
+	}
+	if _, ok := tree.(map[string]any); !ok {
+		if c.lookup != nil {
+			schema, err := c.lookup(u.GetPath())
+			if err != nil {
+				return nil, err
+			}
+			if schema.GetContainer() != nil {
+				return nil, fmt.Errorf("json value of a container must be an object, got %s", raw)
+			}
+		}
+		return []*Update{u}, nil
+	}
+	if c.lookup == nil {
+		return nil, fmt.Errorf("a schema lookup is required to decompose json object values")
+	}
+	return JSONTreeToUpdates(u.GetPath(), tree, c.lookup)
+}
+
+func setLeaf(leaves *PathTrie[*TypedValue], p *Path, v *TypedValue) error {
+	// a leaf can neither be the parent of another leaf nor be below another leaf
+	if lp, _, ok := leaves.LongestPrefix(p); ok && !lp.PathsEqual(p) {
+		return fmt.Errorf("%s is a leaf and can not have children", lp.ToXPath(false))
+	}
+	for lp := range leaves.Descendants(p) {
+		if !lp.PathsEqual(p) {
+			return fmt.Errorf("%s is not a leaf, it has children such as %s", p.ToXPath(false), lp.ToXPath(false))
+		}
+	}
+	leaves.Insert(p, v)
+	return nil
+}
+
+// Get returns the leafs at or below the given path, sorted by path.
+func (c *ConfigTree) Get(p *Path) []*Update {
+	result := []*Update{}
+	for lp, v := range c.leaves.Descendants(p) {
+		result = append(result, &Update{Path: lp, Value: v})
+	}
+	slices.SortFunc(result, func(a, b *Update) int {
+		return ComparePath(a.GetPath(), b.GetPath())
+	})
+	return result
+}
+
+// Notification returns the whole content of the tree as a Notification, with the updates sorted by path.
+func (c *ConfigTree) Notification() *Notification {
+	result := &Notification{
+		Timestamp: c.timestamp,
+		Update:    make([]*Update, 0, c.leaves.Len()),
+	}
+	for p, v := range c.leaves.All() {
+		result.Update = append(result.Update, &Update{Path: p, Value: v})
+	}
+	slices.SortFunc(result.Update, func(a, b *Update) int {
+		return ComparePath(a.GetPath(), b.GetPath())
+	})
+	return result
+}
@@ -0,0 +1,196 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func configTreeContent(ct *ConfigTree) []string {
+	result := []string{}
+	for _, u := range ct.Notification().GetUpdate() {
+		result = append(result, u.GetPath().ToXPath(false)+": "+u.GetValue().ToString())
+	}
+	return result
+}
+
+func TestConfigTree_Set(t *testing.T) {
+	strVal := func(s string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: s}}
+	}
+	ct := NewConfigTree(nil)
+	_, err := ct.Set(&SetDataRequest{Update: []*Update{
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/description"), Value: strVal("uplink")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/system/name"), Value: strVal("leaf1")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp, err := ct.Set(&SetDataRequest{
+		// the update is applied after the delete and the replace, even though it is listed first
+		Update: []*Update{
+			{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/mtu"), Value: strVal("9000")},
+		},
+		Replace: []*Update{
+			{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state"), Value: strVal("disable")},
+		},
+		Delete: []*Path{
+			mustParsePath(t, "/interface[name=ethernet-1/2]"),
+			mustParsePath(t, "/does/not/exist"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := []UpdateResult_Operation{}
+	for _, r := range rsp.GetResponse() {
+		ops = append(ops, r.GetOp())
+	}
+	wantOps := []UpdateResult_Operation{UpdateResult_DELETE, UpdateResult_DELETE, UpdateResult_REPLACE, UpdateResult_UPDATE}
+	if !slices.Equal(ops, wantOps) {
+		t.Errorf("operations = %v, want %v", ops, wantOps)
+	}
+	if rsp.GetTimestamp() != ct.Notification().GetTimestamp() {
+		t.Errorf("response timestamp does not match the notification timestamp")
+	}
+
+	want := []string{
+		"/interface[name=ethernet-1/1]/admin-state: disable",
+		"/interface[name=ethernet-1/1]/description: uplink",
+		"/interface[name=ethernet-1/2]/mtu: 9000",
+		"/system/name: leaf1",
+	}
+	if got := configTreeContent(ct); !slices.Equal(got, want) {
+		t.Errorf("content = %v, want %v", got, want)
+	}
+
+	if _, err := ct.Apply(nil, nil, []*Update{
+		{Path: mustParsePath(t, "/system/name/foo"), Value: strVal("x")},
+	}); err == nil {
+		t.Errorf("expected error when adding a child to a leaf")
+	}
+}
+
+func TestConfigTree_ReplaceClearsSubtree(t *testing.T) {
+	strVal := func(s string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: s}}
+	}
+	lookup := testSchemaLookup(map[string]*SchemaElem{
+		"/interface": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "interface",
+			Keys: []*LeafSchema{{Name: "name", Type: &SchemaLeafType{Type: "string"}}},
+		}}},
+		"/interface/name":        {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "name", Type: &SchemaLeafType{Type: "string"}}}},
+		"/interface/description": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "description", Type: &SchemaLeafType{Type: "string"}}}},
+		"/interface/admin-state": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "admin-state", Type: &SchemaLeafType{Type: "string"}}}},
+	})
+	ct := NewConfigTree(lookup)
+	if _, err := ct.Apply(nil, nil, []*Update{
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/description"), Value: strVal("uplink")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/admin-state"), Value: strVal("enable")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ct.Apply(nil, []*Update{
+		{
+			Path:  mustParsePath(t, "/interface[name=ethernet-1/1]"),
+			Value: &TypedValue{Value: &TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{"admin-state": "disable"}`)}},
+		},
+	}, []*Update{
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/description"), Value: strVal("downlink")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/interface[name=ethernet-1/1]/admin-state: disable",
+		"/interface[name=ethernet-1/2]/admin-state: enable",
+		"/interface[name=ethernet-1/2]/description: downlink",
+	}
+	if got := configTreeContent(ct); !slices.Equal(got, want) {
+		t.Errorf("content = %v, want %v", got, want)
+	}
+
+	// a failing transaction leaves the tree untouched
+	if _, err := ct.Apply([]*Path{mustParsePath(t, "/interface")}, nil, []*Update{
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/description/foo"), Value: strVal("x")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/description"), Value: strVal("x")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/description/bar"), Value: strVal("x")},
+	}); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := configTreeContent(ct); !slices.Equal(got, want) {
+		t.Errorf("content after failed transaction = %v, want %v", got, want)
+	}
+
+	got := []string{}
+	for _, u := range ct.Get(mustParsePath(t, "/interface[name=ethernet-1/2]")) {
+		got = append(got, u.GetPath().ToXPath(false))
+	}
+	if want := []string{"/interface[name=ethernet-1/2]/admin-state", "/interface[name=ethernet-1/2]/description"}; !slices.Equal(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+}
+
+func TestConfigTree_JSONValues(t *testing.T) {
+	strVal := func(s string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: s}}
+	}
+	jsonVal := func(s string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_JsonIetfVal{JsonIetfVal: []byte(s)}}
+	}
+	lookup := testSchemaLookup(map[string]*SchemaElem{
+		"/system":             {Schema: &SchemaElem_Container{Container: &ContainerSchema{Name: "system"}}},
+		"/system/name":        {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "name", Type: &SchemaLeafType{Type: "string"}}}},
+		"/system/contact":     {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "contact", Type: &SchemaLeafType{Type: "string"}}}},
+		"/system/clock":       {Schema: &SchemaElem_Container{Container: &ContainerSchema{Name: "clock"}}},
+		"/system/clock/zone":  {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "zone", Type: &SchemaLeafType{Type: "string"}}}},
+		"/system/clock/local": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "local", Type: &SchemaLeafType{Type: "boolean"}}}},
+	})
+	ct := NewConfigTree(lookup)
+	if _, err := ct.Apply(nil, []*Update{
+		{Path: mustParsePath(t, "/system"), Value: jsonVal(`{"name": "leaf1", "clock": {"zone": "UTC"}}`)},
+	}, []*Update{
+		// lands below the container that was set via json
+		{Path: mustParsePath(t, "/system/clock/local"), Value: &TypedValue{Value: &TypedValue_BoolVal{BoolVal: true}}},
+		{Path: mustParsePath(t, "/system/contact"), Value: jsonVal(`"noc"`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/system/clock/local: true",
+		"/system/clock/zone: UTC",
+		`/system/contact: "noc"`,
+		"/system/name: leaf1",
+	}
+	if got := configTreeContent(ct); !slices.Equal(got, want) {
+		t.Errorf("content = %v, want %v", got, want)
+	}
+
+	failing := []struct {
+		name string
+		ct   *ConfigTree
+		u    *Update
+	}{
+		{"invalid json", ct, &Update{Path: mustParsePath(t, "/system"), Value: jsonVal(`{"name": `)}},
+		{"unknown member", ct, &Update{Path: mustParsePath(t, "/system"), Value: jsonVal(`{"location": "x"}`)}},
+		{"scalar on container", ct, &Update{Path: mustParsePath(t, "/system/clock"), Value: jsonVal(`"UTC"`)}},
+		{"object without lookup", NewConfigTree(nil), &Update{Path: mustParsePath(t, "/system"), Value: jsonVal(`{"name": "leaf1"}`)}},
+	}
+	for _, tt := range failing {
+		t.Run(tt.name, func(t *testing.T) {
+			before := configTreeContent(tt.ct)
+			if _, err := tt.ct.Apply(nil, nil, []*Update{
+				{Path: mustParsePath(t, "/system/name"), Value: strVal("leaf2")},
+				tt.u,
+			}); err == nil {
+				t.Fatal("expected error")
+			}
+			if got := configTreeContent(tt.ct); !slices.Equal(got, before) {
+				t.Errorf("content after failed transaction = %v, want %v", got, before)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package sdcpb
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is an exact decimal number of arbitrary size, its value is unscaled * 10^-scale.
+// The zero value represents 0. Decimals are immutable, all operations return a new Decimal.
+type Decimal struct {
+	unscaled *big.Int
+	scale    uint32
+}
+
+// RoundingMode defines how Rescale rounds when digits are dropped.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfAwayFromZero rounds to the nearest value, ties away from zero
+	RoundHalfAwayFromZero
+	// RoundTowardZero truncates the dropped digits
+	RoundTowardZero
+	// RoundAwayFromZero rounds up the magnitude if any non-zero digit is dropped
+	RoundAwayFromZero
+)
+
+// NewDecimal returns the Decimal unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale uint32) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// ParseDecimal parses a decimal number in the YANG decimal64 lexical form, which is an optional sign,
+// followed by digits with an optional decimal point. The number of fraction digits given is kept as the scale.
+func ParseDecimal(s string) (Decimal, error) {
+	v := strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(v, "-") {
+		neg = true
+		v = v[1:]
+	} else if strings.HasPrefix(v, "+") {
+		v = v[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(v, ".")
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: no digits", s)
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return Decimal{}, fmt.Errorf("invalid decimal %q: unexpected character %q", s, r)
+		}
+	}
+	unscaled, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{unscaled: unscaled, scale: uint32(len(fracPart))}, nil
+}
+
+// ToDecimal converts the Decimal64 into a Decimal.
+func (x *Decimal64) ToDecimal() Decimal {
+	return NewDecimal(x.GetDigits(), x.GetPrecision())
+}
+
+// ToDecimal64 converts the Decimal into a Decimal64, trailing zeros are dropped if the digits exceed 64 bit otherwise.
+func (d Decimal) ToDecimal64() (*Decimal64, error) {
+	unscaled, scale := d.int(), d.scale
+	ten := big.NewInt(10)
+	rem := new(big.Int)
+	for !unscaled.IsInt64() && scale > 0 {
+		q, r := new(big.Int).QuoRem(unscaled, ten, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		unscaled, scale = q, scale-1
+	}
+	if !unscaled.IsInt64() {
+		return nil, fmt.Errorf("decimal %s exceeds the decimal64 range", d.String())
+	}
+	return &Decimal64{Digits: unscaled.Int64(), Precision: scale}, nil
+}
+
+// Scale returns the number of fraction digits of the Decimal.
+func (d Decimal) Scale() uint32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Rat returns the exact value of the Decimal as a big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+}
+
+// String returns the YANG canonical form, which has no trailing zeros in the fraction
+// and at least one digit on either side of the decimal point, e.g. "0.0" or "-12.5".
+func (d Decimal) String() string {
+	unscaled, scale := d.trimmed()
+	if scale == 0 {
+		return unscaled.String() + ".0"
+	}
+	return formatScaled(unscaled, scale)
+}
+
+// Text returns the Decimal with exactly scale fraction digits, without a decimal point if the scale is 0.
+func (d Decimal) Text() string {
+	if d.scale == 0 {
+		return d.int().String()
+	}
+	return formatScaled(d.int(), d.scale)
+}
+
+// MarshalJSON encodes the Decimal as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.Text()), nil
+}
+
+// Cmp compares d and other and returns -1, 0 or +1, irrespective of their scales.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := alignScales(d, other)
+	return a.Cmp(b)
+}
+
+// Add returns d + other, with the larger scale of the two.
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, scale := alignScales(d, other)
+	return Decimal{unscaled: a.Add(a, b), scale: scale}
+}
+
+// Sub returns d - other, with the larger scale of the two.
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := alignScales(d, other)
+	return Decimal{unscaled: a.Sub(a, b), scale: scale}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	unscaled := d.int()
+	return Decimal{unscaled: unscaled.Neg(unscaled), scale: d.scale}
+}
+
+// Rescale returns d with exactly fractionDigits fraction digits, dropped digits are rounded according to mode.
+func (d Decimal) Rescale(fractionDigits uint32, mode RoundingMode) Decimal {
+	if fractionDigits >= d.scale {
+		unscaled := d.int()
+		return Decimal{unscaled: unscaled.Mul(unscaled, pow10(fractionDigits-d.scale)), scale: fractionDigits}
+	}
+	divisor := pow10(d.scale - fractionDigits)
+	// QuoRem truncates toward zero, the remainder carries the sign of d
+	q, r := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+	if r.Sign() == 0 {
+		return Decimal{unscaled: q, scale: fractionDigits}
+	}
+	// compare twice the magnitude of the remainder with the divisor to locate the tie
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	var roundUp bool
+	switch mode {
+	case RoundTowardZero:
+		roundUp = false
+	case RoundAwayFromZero:
+		roundUp = true
+	case RoundHalfAwayFromZero:
+		roundUp = half.Cmp(divisor) >= 0
+	default:
+		c := half.Cmp(divisor)
+		roundUp = c > 0 || c == 0 && q.Bit(0) == 1
+	}
+	if roundUp {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{unscaled: q, scale: fractionDigits}
+}
+
+// int returns a copy of the unscaled value.
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// trimmed returns the unscaled value and scale with trailing fraction zeros removed.
+func (d Decimal) trimmed() (*big.Int, uint32) {
+	unscaled, scale := d.int(), d.scale
+	ten := big.NewInt(10)
+	q, r := new(big.Int), new(big.Int)
+	for scale > 0 {
+		q.QuoRem(unscaled, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		unscaled.Set(q)
+		scale--
+	}
+	return unscaled, scale
+}
+
+// alignScales returns the unscaled values of a and b rescaled to the larger scale of the two.
+func alignScales(a, b Decimal) (*big.Int, *big.Int, uint32) {
+	scale := max(a.scale, b.scale)
+	ua, ub := a.int(), b.int()
+	ua.Mul(ua, pow10(scale-a.scale))
+	ub.Mul(ub, pow10(scale-b.scale))
+	return ua, ub, scale
+}
+
+// formatScaled formats unscaled with a decimal point inserted scale digits from the right.
+func formatScaled(unscaled *big.Int, scale uint32) string {
+	digits := new(big.Int).Abs(unscaled).String()
+	if pad := int(scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(scale)
+	result := digits[:point] + "." + digits[point:]
+	if unscaled.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+func pow10(exp uint32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
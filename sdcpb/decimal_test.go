@@ -0,0 +1,126 @@
+package sdcpb
+
+import (
+	"math"
+	"testing"
+)
+
+func mustParseDecimal(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		text    string
+		canon   string
+		wantErr bool
+	}{
+		{in: "12.340", text: "12.340", canon: "12.34"},
+		{in: "-0.05", text: "-0.05", canon: "-0.05"},
+		{in: "+7", text: "7", canon: "7.0"},
+		{in: ".5", text: "0.5", canon: "0.5"},
+		{in: "3.", text: "3", canon: "3.0"},
+		{in: "0.000", text: "0.000", canon: "0.0"},
+		{in: "123456789012345678901234567890.5", text: "123456789012345678901234567890.5", canon: "123456789012345678901234567890.5"},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "0x10", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, err := ParseDecimal(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseDecimal(%q) wanted error, got %s", tt.in, d.Text())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.Text(); got != tt.text {
+				t.Errorf("Text() = %q, want %q", got, tt.text)
+			}
+			if got := d.String(); got != tt.canon {
+				t.Errorf("String() = %q, want %q", got, tt.canon)
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := mustParseDecimal(t, "1.05")
+	b := mustParseDecimal(t, "-2.5")
+
+	if got := a.Add(b).Text(); got != "-1.45" {
+		t.Errorf("Add() = %s, want -1.45", got)
+	}
+	if got := a.Sub(b).Text(); got != "3.55" {
+		t.Errorf("Sub() = %s, want 3.55", got)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 {
+		t.Errorf("Cmp() ordering wrong for %s and %s", a, b)
+	}
+	if c := mustParseDecimal(t, "2.50").Cmp(mustParseDecimal(t, "2.5")); c != 0 {
+		t.Errorf("Cmp() of 2.50 and 2.5 = %d, want 0", c)
+	}
+	if got := (Decimal{}).String(); got != "0.0" {
+		t.Errorf("zero value String() = %q, want 0.0", got)
+	}
+}
+
+func TestDecimalRescale(t *testing.T) {
+	tests := []struct {
+		in   string
+		fd   uint32
+		mode RoundingMode
+		want string
+	}{
+		{"1.25", 1, RoundHalfEven, "1.2"},
+		{"1.35", 1, RoundHalfEven, "1.4"},
+		{"-1.25", 1, RoundHalfEven, "-1.2"},
+		{"1.25", 1, RoundHalfAwayFromZero, "1.3"},
+		{"-1.25", 1, RoundHalfAwayFromZero, "-1.3"},
+		{"1.29", 1, RoundTowardZero, "1.2"},
+		{"-1.29", 1, RoundTowardZero, "-1.2"},
+		{"1.21", 1, RoundAwayFromZero, "1.3"},
+		{"-1.21", 1, RoundAwayFromZero, "-1.3"},
+		{"1.20", 1, RoundAwayFromZero, "1.2"},
+		{"1.5", 3, RoundHalfEven, "1.500"},
+		{"0.5", 0, RoundHalfEven, "0"},
+	}
+	for _, tt := range tests {
+		got := mustParseDecimal(t, tt.in).Rescale(tt.fd, tt.mode).Text()
+		if got != tt.want {
+			t.Errorf("Rescale(%s, %d, %d) = %s, want %s", tt.in, tt.fd, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalDecimal64Conversion(t *testing.T) {
+	d64, err := mustParseDecimal(t, "-922337203685477580.70").ToDecimal64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d64.GetDigits() != -9223372036854775807 || d64.GetPrecision() != 1 {
+		t.Errorf("ToDecimal64() = %v, want digits -9223372036854775807 precision 1", d64)
+	}
+	if _, err := mustParseDecimal(t, "92233720368547758.08").ToDecimal64(); err == nil {
+		t.Errorf("ToDecimal64() wanted overflow error")
+	}
+
+	// comparing values of high precision must not overflow
+	a := &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: math.MaxInt64, Precision: 0}}}
+	b := &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 1, Precision: 18}}}
+	if a.Cmp(b) != 1 {
+		t.Errorf("Cmp() of %s and %s = %d, want 1", a.ToString(), b.ToString(), a.Cmp(b))
+	}
+}
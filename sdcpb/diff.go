@@ -0,0 +1,66 @@
+package sdcpb
+
+import "slices"
+
+// UpdatesDiff is the result of comparing two sets of updates. All slices are sorted via ComparePath.
+type UpdatesDiff struct {
+	// Changed holds the leafs present in both sets with different values.
+	Changed []*DiffUpdate
+	// Added holds the updates only present in the candidate set.
+	Added []*Update
+	// Removed holds the paths only present in the main set.
+	Removed []*Path
+}
+
+// IsEmpty returns true if no differences were found.
+func (d *UpdatesDiff) IsEmpty() bool {
+	return len(d.Changed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DiffUpdates compares the main and the candidate updates leaf by leaf, using TypedValue.Equal
+// to detect changed values. If a path is present multiple times in one of the sets, the last update wins.
+func DiffUpdates(main, candidate []*Update) *UpdatesDiff {
+	mainIdx := NewPathTrie[*Update]()
+	for _, u := range main {
+		mainIdx.Insert(u.GetPath(), u)
+	}
+	candidateIdx := NewPathTrie[*Update]()
+	for _, u := range candidate {
+		candidateIdx.Insert(u.GetPath(), u)
+	}
+
+	result := &UpdatesDiff{}
+	for p, cu := range candidateIdx.All() {
+		mu, exists := mainIdx.Get(p)
+		switch {
+		case !exists:
+			result.Added = append(result.Added, cu)
+		case !mu.GetValue().Equal(cu.GetValue()):
+			result.Changed = append(result.Changed, &DiffUpdate{
+				Path:           p,
+				MainValue:      mu.GetValue(),
+				CandidateValue: cu.GetValue(),
+			})
+		}
+	}
+	for p := range mainIdx.All() {
+		if !candidateIdx.Contains(p) {
+			result.Removed = append(result.Removed, p)
+		}
+	}
+
+	slices.SortFunc(result.Changed, func(a, b *DiffUpdate) int {
+		return ComparePath(a.GetPath(), b.GetPath())
+	})
+	slices.SortFunc(result.Added, func(a, b *Update) int {
+		return ComparePath(a.GetPath(), b.GetPath())
+	})
+	slices.SortFunc(result.Removed, ComparePath)
+	return result
+}
+
+// DiffNotifications compares the updates of the main and the candidate notification, see DiffUpdates.
+// The notifications are treated as full snapshots, their delete lists are not considered.
+func DiffNotifications(main, candidate *Notification) *UpdatesDiff {
+	return DiffUpdates(main.GetUpdate(), candidate.GetUpdate())
+}
@@ -0,0 +1,54 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffUpdates(t *testing.T) {
+	strVal := func(s string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: s}}
+	}
+	main := []*Update{
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/description"), Value: strVal("uplink")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/2]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/system/name"), Value: strVal("leaf1")},
+	}
+	candidate := []*Update{
+		{Path: mustParsePath(t, "/system/name"), Value: strVal("leaf1")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/3]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/description"), Value: strVal("downlink")},
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state"), Value: strVal("disable")},
+	}
+
+	diff := DiffNotifications(&Notification{Update: main}, &Notification{Update: candidate})
+
+	changed := []string{}
+	for _, c := range diff.Changed {
+		changed = append(changed, c.GetPath().ToXPath(false)+": "+c.GetMainValue().ToString()+" -> "+c.GetCandidateValue().ToString())
+	}
+	wantChanged := []string{
+		"/interface[name=ethernet-1/1]/admin-state: enable -> disable",
+		"/interface[name=ethernet-1/1]/description: uplink -> downlink",
+	}
+	if !slices.Equal(changed, wantChanged) {
+		t.Errorf("Changed = %v, want %v", changed, wantChanged)
+	}
+
+	added := []string{}
+	for _, u := range diff.Added {
+		added = append(added, u.GetPath().ToXPath(false))
+	}
+	if want := []string{"/interface[name=ethernet-1/3]/admin-state"}; !slices.Equal(added, want) {
+		t.Errorf("Added = %v, want %v", added, want)
+	}
+
+	if got, want := Paths(diff.Removed).ToXPathSlice(), []string{"/interface[name=ethernet-1/2]/admin-state"}; !slices.Equal(got, want) {
+		t.Errorf("Removed = %v, want %v", got, want)
+	}
+
+	if !DiffUpdates(main, main).IsEmpty() {
+		t.Errorf("diff of identical updates is not empty")
+	}
+}
@@ -0,0 +1,214 @@
+package sdcpb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// LeafrefValueLookup returns the values of the leaf referenced by the given path, or no values if the leaf does not exist.
+type LeafrefValueLookup func(p *Path) ([]*TypedValue, error)
+
+// leafrefStep is a single step of a leafref path, either ".." or a node identifier with optional predicates.
+type leafrefStep struct {
+	name       string
+	predicates []leafrefPredicate
+}
+
+// leafrefPredicate is a path predicate of the form [key = current()/../node/...].
+type leafrefPredicate struct {
+	key string
+	// up is the number of ".." steps following current()
+	up    int
+	names []string
+}
+
+// ResolveLeafref resolves the leafref path, as defined in SchemaLeafType.Leafref, for the leaf instance at current.
+// Relative paths are resolved against current, the current() predicates are evaluated via the lookup.
+// Module prefixes are removed from the node identifiers. Lists without predicates remain without keys in the target path.
+// Multiple target paths are returned if a predicate evaluates to multiple values, none if it evaluates to no value.
+func ResolveLeafref(leafref string, current *Path, lookup LeafrefValueLookup) ([]*Path, error) {
+	steps, absolute, err := parseLeafrefPath(leafref)
+	if err != nil {
+		return nil, err
+	}
+	base := &Path{Origin: current.GetOrigin(), Target: current.GetTarget(), IsRootBased: true}
+	if !absolute {
+		base.Elem = slices.Clone(current.GetElem())
+	}
+
+	results := []*Path{base}
+	for _, s := range steps {
+		if s.name == ".." {
+			for _, r := range results {
+				if len(r.Elem) == 0 {
+					return nil, fmt.Errorf("leafref %q: path leaves the root of %s", leafref, current.ToXPath(false))
+				}
+				r.Elem = r.Elem[:len(r.Elem)-1]
+			}
+			continue
+		}
+		keySets := []map[string]string{nil}
+		for _, pred := range s.predicates {
+			values, err := pred.evaluate(current, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("leafref %q: %w", leafref, err)
+			}
+			next := make([]map[string]string, 0, len(keySets)*len(values))
+			for _, ks := range keySets {
+				for _, v := range values {
+					keys := copyMap(ks)
+					if keys == nil {
+						keys = map[string]string{}
+					}
+					keys[pred.key] = v
+					next = append(next, keys)
+				}
+			}
+			keySets = next
+		}
+		next := make([]*Path, 0, len(results)*len(keySets))
+		for _, r := range results {
+			for _, ks := range keySets {
+				next = append(next, r.CopyPathAddElem(NewPathElem(s.name, ks)))
+			}
+		}
+		results = next
+	}
+	return results, nil
+}
+
+// evaluate returns the string values of the leaf referenced by the predicate, relative to the current leaf instance.
+func (lp leafrefPredicate) evaluate(current *Path, lookup LeafrefValueLookup) ([]string, error) {
+	if lp.up > len(current.GetElem()) {
+		return nil, fmt.Errorf("predicate of %s leaves the root of %s", lp.key, current.ToXPath(false))
+	}
+	p := &Path{
+		Origin:      current.GetOrigin(),
+		Target:      current.GetTarget(),
+		IsRootBased: true,
+		Elem:        slices.Clone(current.GetElem()[:len(current.GetElem())-lp.up]),
+	}
+	for _, name := range lp.names {
+		p.Elem = append(p.Elem, &PathElem{Name: name})
+	}
+	tvs, err := lookup(p)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(tvs))
+	for _, tv := range tvs {
+		if ll := tv.GetLeaflistVal(); ll != nil {
+			for _, e := range ll.GetElement() {
+				result = append(result, e.ToString())
+			}
+			continue
+		}
+		result = append(result, tv.ToString())
+	}
+	return result, nil
+}
+
+// parseLeafrefPath parses a leafref path as defined in RFC 7950 section 9.9.2 into its steps.
+func parseLeafrefPath(s string) ([]leafrefStep, bool, error) {
+	s = strings.TrimSpace(s)
+	absolute := strings.HasPrefix(s, "/")
+	if absolute {
+		s = s[1:]
+	}
+	steps := []leafrefStep{}
+	depth := 0
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch s[i] {
+			case '[':
+				depth++
+				continue
+			case ']':
+				depth--
+				continue
+			case '/':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		step, err := parseLeafrefStep(strings.TrimSpace(s[start:i]))
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid leafref path %q: %w", s, err)
+		}
+		steps = append(steps, step)
+		start = i + 1
+	}
+	if depth != 0 {
+		return nil, false, fmt.Errorf("invalid leafref path %q: unbalanced brackets", s)
+	}
+	return steps, absolute, nil
+}
+
+func parseLeafrefStep(s string) (leafrefStep, error) {
+	if s == ".." {
+		return leafrefStep{name: s}, nil
+	}
+	name, rest, _ := strings.Cut(s, "[")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return leafrefStep{}, fmt.Errorf("empty node identifier in step %q", s)
+	}
+	_, name = splitModuleName(name)
+	step := leafrefStep{name: name}
+	for rest != "" {
+		content, after, found := strings.Cut(rest, "]")
+		if !found {
+			return leafrefStep{}, fmt.Errorf("unterminated predicate in step %q", s)
+		}
+		pred, err := parseLeafrefPredicate(content)
+		if err != nil {
+			return leafrefStep{}, err
+		}
+		step.predicates = append(step.predicates, pred)
+		after = strings.TrimSpace(after)
+		if after == "" {
+			break
+		}
+		if after[0] != '[' {
+			return leafrefStep{}, fmt.Errorf("unexpected %q after predicate in step %q", after, s)
+		}
+		rest = after[1:]
+	}
+	return step, nil
+}
+
+func parseLeafrefPredicate(s string) (leafrefPredicate, error) {
+	key, expr, found := strings.Cut(s, "=")
+	if !found {
+		return leafrefPredicate{}, fmt.Errorf("predicate %q is not a key comparison", s)
+	}
+	_, key = splitModuleName(strings.TrimSpace(key))
+	parts := strings.Split(expr, "/")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if parts[0] != "current()" {
+		return leafrefPredicate{}, fmt.Errorf("predicate %q does not start with current()", s)
+	}
+	pred := leafrefPredicate{key: key}
+	for _, part := range parts[1:] {
+		switch {
+		case part == ".." && len(pred.names) == 0:
+			pred.up++
+		case part == "" || part == "..":
+			return leafrefPredicate{}, fmt.Errorf("invalid path in predicate %q", s)
+		default:
+			_, name := splitModuleName(part)
+			pred.names = append(pred.names, name)
+		}
+	}
+	if pred.up == 0 || len(pred.names) == 0 {
+		return leafrefPredicate{}, fmt.Errorf("predicate %q must reference a node via current()/..", s)
+	}
+	return pred, nil
+}
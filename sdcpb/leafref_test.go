@@ -0,0 +1,78 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveLeafref(t *testing.T) {
+	values := map[string][]*TypedValue{
+		"/system/ref[id=1]/ifname": {{Value: &TypedValue_StringVal{StringVal: "eth0"}}},
+		"/system/ref[id=1]/subif":  {{Value: &TypedValue_UintVal{UintVal: 5}}},
+		"/system/ref[id=1]/ifnames": {{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+			{Value: &TypedValue_StringVal{StringVal: "eth1"}},
+			{Value: &TypedValue_StringVal{StringVal: "eth2"}},
+		}}}}},
+	}
+	lookup := func(p *Path) ([]*TypedValue, error) {
+		return values[p.ToXPath(false)], nil
+	}
+	current := mustParsePath(t, "/system/ref[id=1]/subif")
+
+	tests := []struct {
+		name    string
+		leafref string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "relative with current predicate",
+			leafref: "../../../interface[name=current()/../ifname]/subinterface/index",
+			want:    []string{"/interface[name=eth0]/subinterface/index"},
+		},
+		{
+			name:    "absolute with prefixes and multiple predicates",
+			leafref: "/if:interface[if:name = current()/../ifname]/if:subinterface[if:index = current()/../subif]/if:index",
+			want:    []string{"/interface[name=eth0]/subinterface[index=5]/index"},
+		},
+		{
+			name:    "list without predicate",
+			leafref: "/interface/name",
+			want:    []string{"/interface/name"},
+		},
+		{
+			name:    "multiple values",
+			leafref: "/interface[name=current()/../ifnames]/name",
+			want:    []string{"/interface[name=eth1]/name", "/interface[name=eth2]/name"},
+		},
+		{
+			name:    "missing value",
+			leafref: "/interface[name=current()/../unknown]/name",
+			want:    []string{},
+		},
+		{
+			name:    "literal predicate",
+			leafref: "/interface[name='eth0']/name",
+			wantErr: true,
+		},
+		{
+			name:    "above the root",
+			leafref: "../../../../interface/name",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := ResolveLeafref(tt.leafref, current, lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveLeafref() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := Paths(paths).ToXPathSlice(); !slices.Equal(got, tt.want) {
+				t.Errorf("ResolveLeafref() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
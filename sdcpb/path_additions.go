@@ -52,6 +52,9 @@ func (p *Path) CopyPathAddKey(keyName string, keyValue string) *Path {
 	return child
 }
 
+// ToXPath returns the xpath representation of the path. Key values that contain brackets, backslashes,
+// leading quotes or surrounding blanks, as well as empty values, are quoted, so for paths without
+// origin and target ParsePath(p.ToXPath(false)) always returns a path equal to p.
 func (p *Path) ToXPath(noKeys bool) string {
 	if p == nil {
 		return ""
@@ -86,7 +89,7 @@ func (p *Path) ToXPath(noKeys bool) string {
 				sb.WriteString("[")
 				sb.WriteString(k)
 				sb.WriteString("=")
-				sb.WriteString(kvMap[k])
+				writeXPathKeyValue(&sb, kvMap[k])
 				sb.WriteString("]")
 			}
 		}
@@ -322,7 +325,7 @@ func ParsePath(p string) (*Path, error) {
 	isRootBased := p[0] == '/'
 
 	idx := strings.Index(p, ":")
-	if idx >= 0 && p[0] != '/' && !strings.Contains(p[:idx], "/") &&
+	if idx >= 0 && p[0] != '/' && !strings.ContainsAny(p[:idx], "/[") &&
 		// path == origin:/ || path == origin:
 		((idx+1 < lp && p[idx+1] == '/') || (lp == idx+1)) {
 		origin = p[:idx]
@@ -349,46 +352,113 @@ func (p *Path) hasRelativePathElem() bool {
 	return false
 }
 
+// writeXPathKeyValue writes the value of a key predicate. Values that could not be parsed back verbatim are quoted,
+// using single quotes or, if the value contains only single quotes, double quotes.
+// Within the quotes a backslash escapes the quote character and the backslash itself.
+func writeXPathKeyValue(sb *strings.Builder, v string) {
+	if v != "" && strings.TrimSpace(v) == v && !strings.ContainsAny(v, `[]\`) && v[0] != '\'' && v[0] != '"' {
+		sb.WriteString(v)
+		return
+	}
+	quote := byte('\'')
+	if strings.Contains(v, "'") && !strings.Contains(v, `"`) {
+		quote = '"'
+	}
+	sb.WriteByte(quote)
+	for i := 0; i < len(v); i++ {
+		if v[i] == quote || v[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(v[i])
+	}
+	sb.WriteByte(quote)
+}
+
+// xpathScanner tracks the state while scanning a xpath byte by byte, to identify the structural characters,
+// that are the brackets of the key predicates and the slashes separating the path elements.
+// Characters escaped by a backslash and characters within a quoted key value are never structural.
+type xpathScanner struct {
+	inKey bool
+	// seenEq is set once the '=' of the current key predicate is seen
+	seenEq bool
+	// valueStart is set between the '=' and the first non blank character of the key value
+	valueStart bool
+	quote      byte
+	escaped    bool
+}
+
+// next advances the scanner by the character c and returns true if c is a structural character.
+func (s *xpathScanner) next(c byte) (bool, error) {
+	switch {
+	case s.escaped:
+		s.escaped = false
+		return false, nil
+	case c == '\\':
+		s.escaped = true
+		return false, nil
+	case s.quote != 0:
+		if c == s.quote {
+			s.quote = 0
+		}
+		return false, nil
+	}
+	if s.valueStart {
+		switch c {
+		case ' ', '\t':
+			return false, nil
+		case '\'', '"':
+			s.valueStart = false
+			s.quote = c
+			return false, nil
+		}
+		s.valueStart = false
+	}
+	switch c {
+	case '[':
+		if s.inKey {
+			return false, errMalformedXPath
+		}
+		s.inKey = true
+		s.seenEq = false
+		return true, nil
+	case ']':
+		if !s.inKey {
+			return false, errMalformedXPath
+		}
+		s.inKey = false
+		s.valueStart = false
+		return true, nil
+	case '=':
+		if s.inKey && !s.seenEq {
+			s.seenEq = true
+			s.valueStart = true
+		}
+	case '/':
+		return !s.inKey, nil
+	}
+	return false, nil
+}
+
 // toPathElems parses a xpath and returns a list of path elements
 func toPathElems(p string) ([]*PathElem, error) {
-	if !strings.HasSuffix(p, "/") {
-		p += "/"
-	}
-	buffer := make([]rune, 0)
-	null := rune(0)
-	prevC := rune(0)
-	// track if the loop is traversing a key
-	inKey := false
-	for _, r := range p {
-		switch r {
-		case '[':
-			if inKey && prevC != '\\' {
-				return nil, errMalformedXPath
-			}
-			if prevC != '\\' {
-				inKey = true
-			}
-		case ']':
-			if !inKey && prevC != '\\' {
-				return nil, errMalformedXPath
-			}
-			if prevC != '\\' {
-				inKey = false
-			}
-		case '/':
-			if !inKey {
-				buffer = append(buffer, null)
-				prevC = r
-				continue
-			}
+	stringElems := []string{}
+	sc := &xpathScanner{}
+	start := 0
+	for i := 0; i < len(p); i++ {
+		structural, err := sc.next(p[i])
+		if err != nil {
+			return nil, err
+		}
+		if structural && p[i] == '/' {
+			stringElems = append(stringElems, p[start:i])
+			start = i + 1
 		}
-		buffer = append(buffer, r)
-		prevC = r
 	}
-	if inKey {
+	if sc.inKey {
 		return nil, errMalformedXPath
 	}
-	stringElems := strings.Split(string(buffer), string(null))
+	stringElems = append(stringElems, p[start:])
+
 	pElems := make([]*PathElem, 0, len(stringElems))
 	for _, s := range stringElems {
 		if s == "" {
@@ -406,13 +476,12 @@ func toPathElems(p string) ([]*PathElem, error) {
 // toPathElem take a xpath formatted path element such as "elem1[k=v]" and returns the corresponding sdcpb.PathElem
 func toPathElem(s string) (*PathElem, error) {
 	idx := -1
-	prevC := rune(0)
-	for i, r := range s {
-		if r == '[' && prevC != '\\' {
+	sc := &xpathScanner{}
+	for i := 0; i < len(s); i++ {
+		if structural, _ := sc.next(s[i]); structural && s[i] == '[' {
 			idx = i
 			break
 		}
-		prevC = r
 	}
 	var kvs map[string]string
 	if idx > 0 {
@@ -426,54 +495,66 @@ func toPathElem(s string) (*PathElem, error) {
 	return &PathElem{Name: s, Key: kvs}, nil
 }
 
-// parseXPathKeys takes keys definition from an xpath, e.g [k1=v1][k2=v2] and return the keys and values as a map[string]string
+// parseXPathKeys takes keys definition from an xpath, e.g [k1=v1][k2='v2'] and return the keys and values as a map[string]string
 func parseXPathKeys(s string) (map[string]string, error) {
 	if len(s) == 0 {
 		return nil, nil
 	}
 	kvs := make(map[string]string)
-	inKey := false
+	sc := &xpathScanner{}
 	start := 0
-	prevRune := rune(0)
-	for i, r := range s {
-		switch r {
-		case '[':
-			if prevRune == '\\' {
-				prevRune = r
-				continue
-			}
-			if inKey {
-				return nil, errMalformedXPathKey
-			}
-			inKey = true
+	for i := 0; i < len(s); i++ {
+		structural, err := sc.next(s[i])
+		if err != nil {
+			return nil, errMalformedXPathKey
+		}
+		if !structural {
+			continue
+		}
+		if s[i] == '[' {
 			start = i + 1
-		case ']':
-			if prevRune == '\\' {
-				prevRune = r
-				continue
-			}
-			if !inKey {
-				return nil, errMalformedXPathKey
-			}
-			eq := strings.Index(s[start:i], "=")
-			if eq < 0 {
-				return nil, errMalformedXPathKey
-			}
-			k, v := s[start:i][:eq], s[start:i][eq+1:]
-			if len(k) == 0 || len(v) == 0 {
-				return nil, errMalformedXPathKey
-			}
-			kvs[strings.TrimSpace(escapedBracketsReplacer.Replace(k))] = strings.TrimSpace(escapedBracketsReplacer.Replace(v))
-			inKey = false
+			continue
+		}
+		k, v, err := parseXPathKey(s[start:i])
+		if err != nil {
+			return nil, err
 		}
-		prevRune = r
+		kvs[k] = v
 	}
-	if inKey {
+	if sc.inKey {
 		return nil, errMalformedXPathKey
 	}
 	return kvs, nil
 }
 
+// parseXPathKey parses the content of a single key predicate, e.g. k=v or k='v'
+func parseXPathKey(s string) (string, string, error) {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || len(k) == 0 || len(v) == 0 {
+		return "", "", errMalformedXPathKey
+	}
+	k = strings.TrimSpace(escapedBracketsReplacer.Replace(k))
+	v = strings.TrimSpace(v)
+	if v[0] != '\'' && v[0] != '"' {
+		return k, escapedBracketsReplacer.Replace(v), nil
+	}
+	// quoted value, the closing quote has to be the last character
+	sb := strings.Builder{}
+	for i := 1; i < len(v); i++ {
+		switch {
+		case v[i] == '\\' && i+1 < len(v):
+			i++
+		case v[i] == v[0]:
+			if i != len(v)-1 {
+				return "", "", errMalformedXPathKey
+			}
+			return k, sb.String(), nil
+		}
+		sb.WriteByte(v[i])
+	}
+	return "", "", errMalformedXPathKey
+}
+
 func ComparePath(a, b *Path) int {
 	if a == nil && b == nil {
 		return 0
@@ -660,3 +741,31 @@ func (p *Path) SharesPrefix(filter *Path) bool {
 	// All common elements matched
 	return true
 }
+
+// PathFromStrings is the inverse of ToStrings. The hasTarget and hasOrigin flags tell whether the index strings
+// start with a target and an origin, as ToStrings omits them if they are empty. The keyNames are called with the
+// path up to and including each element, without keys, and return the key names if the element is a list;
+// the following index strings are then taken as the key values in alphabetical order of the key names.
+// Pass nil keyNames for index strings created with nokeys. The returned path is root based.
+func PathFromStrings(is []string, hasTarget, hasOrigin bool, keyNames func(*Path) []string) (*Path, error) {
+	p := &Path{IsRootBased: true}
+	if hasTarget {
+		if len(is) == 0 {
+			return nil, errors.New("index strings do not contain a target")
+		}
+		p.Target, is = is[0], is[1:]
+	}
+	if hasOrigin {
+		if len(is) == 0 {
+			return nil, errors.New("index strings do not contain an origin")
+		}
+		p.Origin, is = is[0], is[1:]
+	}
+	var sortedKeyNames func(*Path) []string
+	if keyNames != nil {
+		sortedKeyNames = func(p *Path) []string {
+			return slices.Sorted(slices.Values(keyNames(p)))
+		}
+	}
+	return positionalKeysPath(p, is, sortedKeyNames)
+}
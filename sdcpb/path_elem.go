@@ -2,7 +2,9 @@ package sdcpb
 
 import (
 	"iter"
+	"slices"
 	"sort"
+	"strings"
 )
 
 func NewPathElem(name string, keys map[string]string) *PathElem {
@@ -128,3 +130,28 @@ func (p *PathElem) AddKey(key, value string) {
 	}
 	p.Key[key] = value
 }
+
+// pathElemKeyString returns a canonical string representation of the keys of the PathElem.
+func pathElemKeyString(pe *PathElem) string {
+	switch len(pe.GetKey()) {
+	case 0:
+		return ""
+	case 1:
+		for k, v := range pe.GetKey() {
+			return k + "\x00" + v
+		}
+	}
+	keys := make([]string, 0, len(pe.GetKey()))
+	for k := range pe.GetKey() {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	sb := strings.Builder{}
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(pe.GetKey()[k])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
@@ -0,0 +1,167 @@
+package sdcpb
+
+import "sync"
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+	// hashSeparator is mixed in between the fields, so that e.g. the names "ab","c" and "a","bc" hash differently
+	hashSeparator = 0xff
+)
+
+func hashString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	h ^= hashSeparator
+	h *= fnvPrime64
+	return h
+}
+
+func hashUint64(h uint64, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= v & 0xff
+		h *= fnvPrime64
+		v >>= 8
+	}
+	return h
+}
+
+// mix64 is the splitmix64 finalizer, it spreads the bits of the per key hashes before they are summed up.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// Hash returns a stable 64-bit hash of the path element. The keys are combined independent of their order,
+// so no sorting and no allocation is required. Elements that are Equal have the same hash.
+func (pe *PathElem) Hash() uint64 {
+	return pe.hash(fnvOffset64)
+}
+
+func (pe *PathElem) hash(h uint64) uint64 {
+	h = hashString(h, pe.GetName())
+	var keys uint64
+	for k, v := range pe.GetKey() {
+		keys += mix64(hashString(hashString(fnvOffset64, k), v))
+	}
+	h = hashUint64(h, uint64(len(pe.GetKey())))
+	return hashUint64(h, keys)
+}
+
+// Hash returns a stable 64-bit hash of the path, covering origin, target, the root based flag and all elements.
+// It requires no string formatting and does not allocate. Paths that are Equal have the same hash.
+func (p *Path) Hash() uint64 {
+	h := uint64(fnvOffset64)
+	h = hashString(h, p.GetOrigin())
+	h = hashString(h, p.GetTarget())
+	if p.GetIsRootBased() {
+		h = hashUint64(h, 1)
+	} else {
+		h = hashUint64(h, 0)
+	}
+	for _, pe := range p.GetElem() {
+		h = pe.hash(h)
+	}
+	return h
+}
+
+// Equal returns true if both paths have the same origin, target, root based flag and elements.
+// In contrast to PathsEqual, origin, target and the root based flag are compared as well, matching Hash.
+// Note that go-cmp uses this method when comparing *Path values, so unlike a field wise comparison
+// the keys are compared without their order and nil and empty key maps are considered equal.
+func (p *Path) Equal(other *Path) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	if p.GetOrigin() != other.GetOrigin() || p.GetTarget() != other.GetTarget() || p.GetIsRootBased() != other.GetIsRootBased() {
+		return false
+	}
+	if len(p.GetElem()) != len(other.GetElem()) {
+		return false
+	}
+	for i, pe := range p.GetElem() {
+		if !pathElemEqual(pe, other.GetElem()[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// pathElemEqual compares the path elements without sorting their keys.
+func pathElemEqual(a, b *PathElem) bool {
+	if a == b {
+		return true
+	}
+	if a.GetName() != b.GetName() || len(a.GetKey()) != len(b.GetKey()) {
+		return false
+	}
+	for k, v := range a.GetKey() {
+		if bv, exists := b.GetKey()[k]; !exists || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PathInterner deduplicates PathElem instances across many paths, so that equal elements are shared.
+// Interned elements must not be modified. It is safe for concurrent use.
+type PathInterner struct {
+	mu    sync.Mutex
+	elems map[uint64][]*PathElem
+	size  int
+}
+
+// NewPathInterner returns an empty PathInterner.
+func NewPathInterner() *PathInterner {
+	return &PathInterner{
+		elems: map[uint64][]*PathElem{},
+	}
+}
+
+// InternPathElem returns the canonical instance of the path element, registering pe if no equal element is known.
+func (pi *PathInterner) InternPathElem(pe *PathElem) *PathElem {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	return pi.intern(pe)
+}
+
+func (pi *PathInterner) intern(pe *PathElem) *PathElem {
+	h := pe.Hash()
+	for _, e := range pi.elems[h] {
+		if pathElemEqual(e, pe) {
+			return e
+		}
+	}
+	pi.elems[h] = append(pi.elems[h], pe)
+	pi.size++
+	return pe
+}
+
+// Intern returns a copy of the path whose elements are the canonical instances. The given path is not modified.
+func (pi *PathInterner) Intern(p *Path) *Path {
+	result := &Path{
+		Origin:      p.GetOrigin(),
+		Target:      p.GetTarget(),
+		IsRootBased: p.GetIsRootBased(),
+		Elem:        make([]*PathElem, 0, len(p.GetElem())),
+	}
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	for _, pe := range p.GetElem() {
+		result.Elem = append(result.Elem, pi.intern(pe))
+	}
+	return result
+}
+
+// Len returns the number of distinct path elements known to the interner.
+func (pi *PathInterner) Len() int {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	return pi.size
+}
@@ -0,0 +1,90 @@
+package sdcpb
+
+import "testing"
+
+func TestPath_HashEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  *Path
+		equal bool
+	}{
+		{
+			name:  "key order independent",
+			a:     &Path{IsRootBased: true, Elem: []*PathElem{{Name: "a", Key: map[string]string{"k1": "v1", "k2": "v2"}}}},
+			b:     &Path{IsRootBased: true, Elem: []*PathElem{{Name: "a", Key: map[string]string{"k2": "v2", "k1": "v1"}}}},
+			equal: true,
+		},
+		{
+			name: "swapped key values",
+			a:    &Path{Elem: []*PathElem{{Name: "a", Key: map[string]string{"k1": "v1", "k2": "v2"}}}},
+			b:    &Path{Elem: []*PathElem{{Name: "a", Key: map[string]string{"k1": "v2", "k2": "v1"}}}},
+		},
+		{
+			name: "element boundaries",
+			a:    &Path{Elem: []*PathElem{{Name: "ab"}, {Name: "c"}}},
+			b:    &Path{Elem: []*PathElem{{Name: "a"}, {Name: "bc"}}},
+		},
+		{
+			name: "origin",
+			a:    &Path{Origin: "openconfig", Elem: []*PathElem{{Name: "a"}}},
+			b:    &Path{Elem: []*PathElem{{Name: "a"}}},
+		},
+		{
+			name: "root based",
+			a:    &Path{IsRootBased: true, Elem: []*PathElem{{Name: "a"}}},
+			b:    &Path{Elem: []*PathElem{{Name: "a"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.equal {
+				t.Errorf("Equal() = %t, want %t", got, tt.equal)
+			}
+			if got := tt.a.Hash() == tt.b.Hash(); got != tt.equal {
+				t.Errorf("Hash() equal = %t, want %t", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestPath_HashAllocs(t *testing.T) {
+	p := mustParsePath(t, "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1][afi=ipv4]/peer-as")
+	if allocs := testing.AllocsPerRun(100, func() { p.Hash() }); allocs != 0 {
+		t.Errorf("Hash() allocates %v times", allocs)
+	}
+}
+
+func TestPathInterner(t *testing.T) {
+	pi := NewPathInterner()
+	a := pi.Intern(mustParsePath(t, "/interface[name=ethernet-1/1]/description"))
+	b := pi.Intern(mustParsePath(t, "/interface[name=ethernet-1/1]/mtu"))
+	c := pi.Intern(mustParsePath(t, "/interface[name=ethernet-1/2]/mtu"))
+	if a.GetElem()[0] != b.GetElem()[0] {
+		t.Errorf("equal elements are not shared")
+	}
+	if b.GetElem()[1] != c.GetElem()[1] {
+		t.Errorf("equal elements are not shared")
+	}
+	if a.GetElem()[0] == c.GetElem()[0] {
+		t.Errorf("different elements are shared")
+	}
+	if pi.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", pi.Len())
+	}
+	if !a.Equal(mustParsePath(t, "/interface[name=ethernet-1/1]/description")) {
+		t.Errorf("interned path differs from the original")
+	}
+}
+
+func BenchmarkPath_Hash(b *testing.B) {
+	p := &Path{IsRootBased: true, Elem: []*PathElem{
+		{Name: "network-instance", Key: map[string]string{"name": "default"}},
+		{Name: "protocols"},
+		{Name: "bgp"},
+		{Name: "neighbor", Key: map[string]string{"peer-address": "10.0.0.1", "afi": "ipv4"}},
+		{Name: "peer-as"},
+	}}
+	for b.Loop() {
+		p.Hash()
+	}
+}
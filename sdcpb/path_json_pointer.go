@@ -0,0 +1,95 @@
+package sdcpb
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	jsonPointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	// jsonPointerInvalidEscape matches a '~' that is not followed by '0' or '1'
+	jsonPointerInvalidEscape = regexp.MustCompile(`~([^01]|$)`)
+)
+
+// ToJSONPointer returns the RFC 6901 JSON Pointer representation of the path, e.g. "/interfaces/interface/eth0/mtu".
+// The key values of list elements follow the element name as positional segments, ordered as returned by keyOrder,
+// which is called with the path up to and including the list element, without its keys. If keyOrder is nil or does not
+// return exactly the keys of the element, the keys are ordered alphabetically.
+func (p *Path) ToJSONPointer(keyOrder func(*Path) []string) string {
+	sb := strings.Builder{}
+	current := &Path{Origin: p.GetOrigin(), Target: p.GetTarget(), IsRootBased: true}
+	for _, pe := range p.GetElem() {
+		sb.WriteString("/")
+		sb.WriteString(jsonPointerEscaper.Replace(pe.GetName()))
+		if len(pe.GetKey()) > 0 {
+			var keyNames []string
+			if keyOrder != nil {
+				keyNames = keyOrder(current.CopyPathAddElem(&PathElem{Name: pe.GetName()}))
+			}
+			if !sameKeys(keyNames, pe.GetKey()) {
+				keyNames = slices.Sorted(maps.Keys(pe.GetKey()))
+			}
+			for _, k := range keyNames {
+				sb.WriteString("/")
+				sb.WriteString(jsonPointerEscaper.Replace(pe.GetKey()[k]))
+			}
+		}
+		current = current.CopyPathAddElem(pe)
+	}
+	return sb.String()
+}
+
+// ParseJSONPointer parses a RFC 6901 JSON Pointer into a root based path. The keyOrder is called with the path up to
+// and including each element, without keys, and returns the key names if the element is a list. The following segments
+// are then taken as the values of these keys. A list element at the end of the pointer may omit its key values.
+func ParseJSONPointer(s string, keyOrder func(*Path) []string) (*Path, error) {
+	p := &Path{IsRootBased: true}
+	if s == "" {
+		return p, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q: must start with '/'", s)
+	}
+	if jsonPointerInvalidEscape.MatchString(s) {
+		return nil, fmt.Errorf("invalid json pointer %q: '~' must be followed by '0' or '1'", s)
+	}
+	segments := strings.Split(s[1:], "/")
+	for i := range segments {
+		segments[i] = jsonPointerUnescaper.Replace(segments[i])
+	}
+	return positionalKeysPath(p, segments, keyOrder)
+}
+
+// positionalKeysPath appends the segments to the path, where each element name is followed by the values of its keys,
+// in the order returned by keyNames.
+func positionalKeysPath(p *Path, segments []string, keyNames func(*Path) []string) (*Path, error) {
+	for i := 0; i < len(segments); i++ {
+		name := segments[i]
+		if name == "" {
+			return nil, fmt.Errorf("%s: empty element name", p.ToXPath(false))
+		}
+		var names []string
+		if keyNames != nil {
+			names = keyNames(p.CopyPathAddElem(&PathElem{Name: name}))
+		}
+		// a list without any key values references the whole list
+		if len(names) == 0 || i+1 == len(segments) {
+			p = p.CopyPathAddElem(&PathElem{Name: name})
+			continue
+		}
+		if i+len(names) >= len(segments) {
+			return nil, fmt.Errorf("%s: list %s requires values for the keys %v", p.ToXPath(false), name, names)
+		}
+		keys := make(map[string]string, len(names))
+		for j, k := range names {
+			keys[k] = segments[i+1+j]
+		}
+		i += len(names)
+		p = p.CopyPathAddElem(NewPathElem(name, keys))
+	}
+	return p, nil
+}
@@ -0,0 +1,82 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func testKeyNames(lists map[string][]string) func(*Path) []string {
+	return func(p *Path) []string {
+		return lists[(&Path{IsRootBased: true, Elem: p.GetElem()}).ToXPath(true)]
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	keyOrder := testKeyNames(map[string][]string{
+		"/interfaces/interface": {"name"},
+		"/routing/static/route": {"prefix", "next-hop"},
+	})
+	tests := []struct {
+		name    string
+		xpath   string
+		pointer string
+	}{
+		{name: "single key", xpath: "/interfaces/interface[name=eth0]/mtu", pointer: "/interfaces/interface/eth0/mtu"},
+		{name: "schema key order", xpath: "/routing/static/route[prefix=10.0.0.0/8][next-hop=1.1.1.1]/metric", pointer: "/routing/static/route/10.0.0.0~18/1.1.1.1/metric"},
+		{name: "whole list", xpath: "/interfaces/interface", pointer: "/interfaces/interface"},
+		{name: "root", xpath: "/", pointer: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mustParsePath(t, tt.xpath)
+			if got := p.ToJSONPointer(keyOrder); got != tt.pointer {
+				t.Errorf("ToJSONPointer() = %q, want %q", got, tt.pointer)
+			}
+			got, err := ParseJSONPointer(tt.pointer, keyOrder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, p) {
+				t.Errorf("ParseJSONPointer() = %v, want %v", got, p)
+			}
+		})
+	}
+
+	for _, s := range []string{"interfaces", "/interfaces/a~2b", "/routing/static/route/10.0.0.0~18", "/interfaces//mtu"} {
+		if _, err := ParseJSONPointer(s, keyOrder); err == nil {
+			t.Errorf("ParseJSONPointer(%q) expected error", s)
+		}
+	}
+}
+
+func TestPathFromStrings(t *testing.T) {
+	keyNames := testKeyNames(map[string][]string{
+		"/routing/static/route": {"prefix", "next-hop"},
+	})
+	p := mustParsePath(t, "/routing/static/route[prefix=10.0.0.0/8][next-hop=1.1.1.1]/metric")
+	p.Origin = "openconfig"
+	p.Target = "leaf1"
+
+	is := ToStrings(p, true, false)
+	want := []string{"leaf1", "openconfig", "routing", "static", "route", "1.1.1.1", "10.0.0.0/8", "metric"}
+	if !slices.Equal(is, want) {
+		t.Fatalf("ToStrings() = %v, want %v", is, want)
+	}
+	got, err := PathFromStrings(is, true, true, keyNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(p) {
+		t.Errorf("PathFromStrings() = %v, want %v", got, p)
+	}
+
+	got, err = PathFromStrings(ToStrings(p, false, true), false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/routing/static/route/metric"; got.ToXPath(false) != want {
+		t.Errorf("PathFromStrings() without keys = %s, want %s", got.ToXPath(false), want)
+	}
+}
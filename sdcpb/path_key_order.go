@@ -0,0 +1,133 @@
+package sdcpb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyValue is a single key of a path element.
+type KeyValue struct {
+	Name  string
+	Value string
+}
+
+// SchemaOrderedPath is a path with the keys of its list elements in the order declared by the schema,
+// as required by NETCONF, RESTCONF and CLI renderers. It is created via NormalizeKeyOrder.
+type SchemaOrderedPath struct {
+	Path *Path
+	// Keys holds one key tuple per path element, nil for elements without keys.
+	Keys [][]KeyValue
+}
+
+// NormalizeKeyOrder orders the keys of the path elements as declared in the schema. The schemas hold the
+// ContainerSchema of each path element, entries of elements without keys may be nil.
+// Elements may carry a subset of the declared keys, keys not declared by the schema are an error.
+func NormalizeKeyOrder(p *Path, schemas []*ContainerSchema) (*SchemaOrderedPath, error) {
+	if len(schemas) != len(p.GetElem()) {
+		return nil, fmt.Errorf("%s: %d schemas given for %d path elements", p.ToXPath(false), len(schemas), len(p.GetElem()))
+	}
+	result := &SchemaOrderedPath{
+		Path: p,
+		Keys: make([][]KeyValue, len(p.GetElem())),
+	}
+	for i, pe := range p.GetElem() {
+		if len(pe.GetKey()) == 0 {
+			continue
+		}
+		tuple := make([]KeyValue, 0, len(pe.GetKey()))
+		for _, k := range schemas[i].GetKeys() {
+			if v, exists := pe.GetKey()[k.GetName()]; exists {
+				tuple = append(tuple, KeyValue{Name: k.GetName(), Value: v})
+			}
+		}
+		if len(tuple) != len(pe.GetKey()) {
+			return nil, fmt.Errorf("%s: keys of element %s do not match the schema declared keys", p.ToXPath(false), pe.GetName())
+		}
+		result.Keys[i] = tuple
+	}
+	return result, nil
+}
+
+// ToXPath returns the xpath representation of the path like Path.ToXPath, but with the keys in schema order.
+func (sp *SchemaOrderedPath) ToXPath(noKeys bool) string {
+	if sp == nil || sp.Path == nil {
+		return ""
+	}
+	sb := strings.Builder{}
+	if sp.Path.IsRootBased {
+		sb.WriteString("/")
+	}
+	if sp.Path.Origin != "" {
+		sb.WriteString(sp.Path.Origin)
+		sb.WriteString(":")
+	}
+	for i, pe := range sp.Path.GetElem() {
+		if i > 0 {
+			sb.WriteString("/")
+		}
+		sb.WriteString(pe.GetName())
+		if noKeys {
+			continue
+		}
+		for _, kv := range sp.Keys[i] {
+			sb.WriteString("[")
+			sb.WriteString(kv.Name)
+			sb.WriteString("=")
+			writeXPathKeyValue(&sb, kv.Value)
+			sb.WriteString("]")
+		}
+	}
+	return sb.String()
+}
+
+// CompareSchemaOrderedPath compares the paths like ComparePath, but compares the keys of the path elements
+// in schema order, so that list entries sort the way a device shows them.
+func CompareSchemaOrderedPath(a, b *SchemaOrderedPath) int {
+	if a == nil && b == nil {
+		return 0
+	}
+	if a == nil {
+		return -1
+	}
+	if b == nil {
+		return 1
+	}
+	if c := strings.Compare(a.Path.GetOrigin(), b.Path.GetOrigin()); c != 0 {
+		return c
+	}
+	ae, be := a.Path.GetElem(), b.Path.GetElem()
+	for i := 0; i < min(len(ae), len(be)); i++ {
+		if c := strings.Compare(ae[i].GetName(), be[i].GetName()); c != 0 {
+			return c
+		}
+		if c := compareKeyTuples(a.Keys[i], b.Keys[i]); c != 0 {
+			return c
+		}
+	}
+	if len(ae) != len(be) {
+		return len(ae) - len(be)
+	}
+	if c := strings.Compare(a.Path.GetTarget(), b.Path.GetTarget()); c != 0 {
+		return c
+	}
+	switch {
+	case !a.Path.GetIsRootBased() && b.Path.GetIsRootBased():
+		return -1
+	case a.Path.GetIsRootBased() && !b.Path.GetIsRootBased():
+		return 1
+	}
+	return 0
+}
+
+// compareKeyTuples compares the key tuples key by key, the shorter tuple wins if all common keys are equal.
+func compareKeyTuples(a, b []KeyValue) int {
+	for i := 0; i < min(len(a), len(b)); i++ {
+		if c := strings.Compare(a[i].Name, b[i].Name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a[i].Value, b[i].Value); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,50 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeKeyOrder(t *testing.T) {
+	route := &ContainerSchema{
+		Name: "route",
+		Keys: []*LeafSchema{{Name: "prefix"}, {Name: "next-hop"}},
+	}
+	schemas := []*ContainerSchema{{Name: "static-routes"}, route, {Name: "metric"}}
+
+	xpaths := []string{
+		"/static-routes/route[prefix=10.0.0.0/8][next-hop=192.168.0.2]/metric",
+		"/static-routes/route[prefix=10.0.0.0/8][next-hop=192.168.0.1]/metric",
+		"/static-routes/route[prefix=1.0.0.0/8][next-hop=192.168.0.3]/metric",
+	}
+	paths := []*SchemaOrderedPath{}
+	for _, x := range xpaths {
+		sp, err := NormalizeKeyOrder(mustParsePath(t, x), schemas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, sp)
+	}
+	slices.SortFunc(paths, CompareSchemaOrderedPath)
+
+	got := []string{}
+	for _, sp := range paths {
+		got = append(got, sp.ToXPath(false))
+	}
+	// sorted by prefix first, even though next-hop is first alphabetically
+	want := []string{
+		"/static-routes/route[prefix=1.0.0.0/8][next-hop=192.168.0.3]/metric",
+		"/static-routes/route[prefix=10.0.0.0/8][next-hop=192.168.0.1]/metric",
+		"/static-routes/route[prefix=10.0.0.0/8][next-hop=192.168.0.2]/metric",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("sorted paths = %v, want %v", got, want)
+	}
+
+	if _, err := NormalizeKeyOrder(mustParsePath(t, "/static-routes/route[prefix=1.0.0.0/8][foo=bar]/metric"), schemas); err == nil {
+		t.Errorf("expected error for a key not declared by the schema")
+	}
+	if _, err := NormalizeKeyOrder(mustParsePath(t, "/static-routes/route[prefix=1.0.0.0/8]"), schemas); err == nil {
+		t.Errorf("expected error for a schema count mismatch")
+	}
+}
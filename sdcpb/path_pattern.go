@@ -0,0 +1,180 @@
+package sdcpb
+
+const (
+	// PathWildcard matches exactly one path element when used as element name,
+	// or any value when used as key value.
+	PathWildcard = "*"
+	// PathMultiLevelWildcard matches zero or more path elements.
+	PathMultiLevelWildcard = "..."
+)
+
+// PathPattern is a compiled representation of a Path that may contain gNMI style wildcards.
+// Element names of "*" match any single element, key values of "*" match any value of that key
+// and "..." elements match any number (including zero) of elements.
+// Keys that are not present in the pattern element are not checked, so "interface" matches
+// every entry of the interface list.
+type PathPattern struct {
+	origin      string
+	target      string
+	isRootBased bool
+	elems       []patternElem
+}
+
+type patternElem struct {
+	name       string
+	anyName    bool
+	multiLevel bool
+	keys       map[string]string
+}
+
+// CompilePathPattern compiles the given path into a PathPattern.
+// The path is not referenced by the pattern, later modifications of p do not affect the pattern.
+func CompilePathPattern(p *Path) *PathPattern {
+	pp := &PathPattern{
+		origin:      p.GetOrigin(),
+		target:      p.GetTarget(),
+		isRootBased: p.GetIsRootBased(),
+		elems:       make([]patternElem, 0, len(p.GetElem())),
+	}
+	for _, pe := range p.GetElem() {
+		// subsequent multi level wildcards are equal to a single one
+		if pe.GetName() == PathMultiLevelWildcard && len(pp.elems) > 0 && pp.elems[len(pp.elems)-1].multiLevel {
+			continue
+		}
+		pp.elems = append(pp.elems, patternElem{
+			name:       pe.GetName(),
+			anyName:    pe.GetName() == PathWildcard,
+			multiLevel: pe.GetName() == PathMultiLevelWildcard,
+			keys:       copyMap(pe.GetKey()),
+		})
+	}
+	return pp
+}
+
+// ParsePathPattern parses the xpath formatted pattern and compiles it into a PathPattern.
+func ParsePathPattern(s string) (*PathPattern, error) {
+	p, err := ParsePath(s)
+	if err != nil {
+		return nil, err
+	}
+	return CompilePathPattern(p), nil
+}
+
+// HasWildcard returns true if the path contains any element or key value wildcard.
+func (p *Path) HasWildcard() bool {
+	for _, pe := range p.GetElem() {
+		if pe.GetName() == PathWildcard || pe.GetName() == PathMultiLevelWildcard {
+			return true
+		}
+		for _, v := range pe.GetKey() {
+			if v == PathWildcard {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches returns true if the concrete path is matched by p, treating p as a pattern.
+// For repeated matching against the same pattern use CompilePathPattern.
+func (p *Path) Matches(concrete *Path) bool {
+	if p == nil || concrete == nil {
+		return false
+	}
+	return CompilePathPattern(p).Matches(concrete)
+}
+
+// Matches returns true if the pattern matches the whole concrete path.
+func (pp *PathPattern) Matches(concrete *Path) bool {
+	if !pp.matchesHeader(concrete) {
+		return false
+	}
+	return pp.match(concrete.GetElem(), false)
+}
+
+// MatchesPrefix returns true if the concrete path equals or is a descendant of a path matched by the pattern.
+// This is the subscription semantic of gNMI, where a path also covers its whole subtree.
+func (pp *PathPattern) MatchesPrefix(concrete *Path) bool {
+	if !pp.matchesHeader(concrete) {
+		return false
+	}
+	return pp.match(concrete.GetElem(), true)
+}
+
+// String returns the xpath representation of the pattern.
+func (pp *PathPattern) String() string {
+	p := &Path{
+		Origin:      pp.origin,
+		Target:      pp.target,
+		IsRootBased: pp.isRootBased,
+		Elem:        make([]*PathElem, 0, len(pp.elems)),
+	}
+	for _, e := range pp.elems {
+		p.Elem = append(p.Elem, NewPathElem(e.name, e.keys))
+	}
+	return p.ToXPath(false)
+}
+
+func (pp *PathPattern) matchesHeader(concrete *Path) bool {
+	if pp == nil || concrete == nil {
+		return false
+	}
+	if pp.origin != PathWildcard && pp.origin != concrete.GetOrigin() {
+		return false
+	}
+	if pp.target != PathWildcard && pp.target != concrete.GetTarget() {
+		return false
+	}
+	return pp.isRootBased == concrete.GetIsRootBased()
+}
+
+// match performs a glob style match of the pattern elements against the concrete elements,
+// backtracking to the last multi level wildcard on a mismatch.
+// If prefix is set, trailing concrete elements that are not consumed by the pattern are accepted.
+func (pp *PathPattern) match(elems []*PathElem, prefix bool) bool {
+	pi, ci := 0, 0
+	// position of the last multi level wildcard in the pattern and the concrete
+	// position it was tried against, -1 if none was seen yet
+	starP, starC := -1, -1
+	for ci < len(elems) {
+		switch {
+		case pi < len(pp.elems) && pp.elems[pi].multiLevel:
+			starP, starC = pi, ci
+			pi++
+		case pi < len(pp.elems) && pp.elems[pi].matchElem(elems[ci]):
+			pi++
+			ci++
+		case pi == len(pp.elems) && prefix:
+			return true
+		case starP >= 0:
+			// let the multi level wildcard consume one more element
+			starC++
+			pi, ci = starP+1, starC
+		default:
+			return false
+		}
+	}
+	// remaining pattern elements must all be multi level wildcards
+	for ; pi < len(pp.elems); pi++ {
+		if !pp.elems[pi].multiLevel {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *patternElem) matchElem(pe *PathElem) bool {
+	if !e.anyName && e.name != pe.GetName() {
+		return false
+	}
+	for k, v := range e.keys {
+		cv, ok := pe.GetKey()[k]
+		if !ok {
+			return false
+		}
+		if v != PathWildcard && v != cv {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package sdcpb
+
+import (
+	"testing"
+)
+
+func TestPathPattern_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		concrete string
+		want     bool
+	}{
+		{
+			name:     "exact match",
+			pattern:  "/interface[name=ethernet-1/1]/admin-state",
+			concrete: "/interface[name=ethernet-1/1]/admin-state",
+			want:     true,
+		},
+		{
+			name:     "different key value",
+			pattern:  "/interface[name=ethernet-1/1]/admin-state",
+			concrete: "/interface[name=ethernet-1/2]/admin-state",
+			want:     false,
+		},
+		{
+			name:     "key value wildcard",
+			pattern:  "/interface[name=*]/admin-state",
+			concrete: "/interface[name=ethernet-1/2]/admin-state",
+			want:     true,
+		},
+		{
+			name:     "omitted keys match any list entry",
+			pattern:  "/interface/admin-state",
+			concrete: "/interface[name=ethernet-1/2]/admin-state",
+			want:     true,
+		},
+		{
+			name:     "pattern key missing in concrete",
+			pattern:  "/interface[name=*]/admin-state",
+			concrete: "/interface/admin-state",
+			want:     false,
+		},
+		{
+			name:     "element name wildcard",
+			pattern:  "/interface[name=*]/*",
+			concrete: "/interface[name=ethernet-1/2]/description",
+			want:     true,
+		},
+		{
+			name:     "element name wildcard matches exactly one element",
+			pattern:  "/interface/*",
+			concrete: "/interface[name=ethernet-1/2]/subinterface[index=0]/description",
+			want:     false,
+		},
+		{
+			name:     "multi level wildcard in the middle",
+			pattern:  "/interface/.../description",
+			concrete: "/interface[name=ethernet-1/2]/subinterface[index=0]/description",
+			want:     true,
+		},
+		{
+			name:     "multi level wildcard matches zero elements",
+			pattern:  "/interface/.../description",
+			concrete: "/interface[name=ethernet-1/2]/description",
+			want:     true,
+		},
+		{
+			name:     "multi level wildcard at the end",
+			pattern:  "/interface[name=ethernet-1/2]/...",
+			concrete: "/interface[name=ethernet-1/2]/subinterface[index=0]/description",
+			want:     true,
+		},
+		{
+			name:     "multi level wildcard requires backtracking",
+			pattern:  "/.../a/b",
+			concrete: "/a/x/a/b",
+			want:     true,
+		},
+		{
+			name:     "multi level wildcard no match",
+			pattern:  "/.../a/b",
+			concrete: "/a/x/a/c",
+			want:     false,
+		},
+		{
+			name:     "pattern longer than concrete",
+			pattern:  "/interface/description",
+			concrete: "/interface",
+			want:     false,
+		},
+		{
+			name:     "concrete longer than pattern",
+			pattern:  "/interface",
+			concrete: "/interface/description",
+			want:     false,
+		},
+		{
+			name:     "root based mismatch",
+			pattern:  "interface",
+			concrete: "/interface",
+			want:     false,
+		},
+		{
+			name:     "origin mismatch",
+			pattern:  "openconfig:/interfaces",
+			concrete: "/interfaces",
+			want:     false,
+		},
+		{
+			name:     "only multi level wildcard",
+			pattern:  "/...",
+			concrete: "/",
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := ParsePath(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			concrete, err := ParsePath(tt.concrete)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pattern.Matches(concrete); got != tt.want {
+				t.Errorf("Matches(%s, %s) = %v, want %v", tt.pattern, tt.concrete, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathPattern_MatchesPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		concrete string
+		want     bool
+	}{
+		{
+			name:     "same path",
+			pattern:  "/interface[name=*]",
+			concrete: "/interface[name=ethernet-1/1]",
+			want:     true,
+		},
+		{
+			name:     "descendant",
+			pattern:  "/interface[name=*]",
+			concrete: "/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+			want:     true,
+		},
+		{
+			name:     "ancestor",
+			pattern:  "/interface[name=*]/subinterface",
+			concrete: "/interface[name=ethernet-1/1]",
+			want:     false,
+		},
+		{
+			name:     "descendant of multi level match",
+			pattern:  "/.../subinterface[index=0]",
+			concrete: "/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+			want:     true,
+		},
+		{
+			name:     "diverging",
+			pattern:  "/interface[name=*]/subinterface[index=1]",
+			concrete: "/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pp, err := ParsePathPattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			concrete, err := ParsePath(tt.concrete)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pp.MatchesPrefix(concrete); got != tt.want {
+				t.Errorf("MatchesPrefix(%s, %s) = %v, want %v", tt.pattern, tt.concrete, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package sdcpb
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ToRESTCONF returns the RFC 8040 api-path representation of the path, relative to the {+restconf}/data resource,
+// e.g. "module:container/list=k1,k2/leaf". Key values are percent-encoded and ordered as returned by keyOrder,
+// which is called with the path up to and including the list element, without its keys. If keyOrder is nil or does not
+// return exactly the keys of the element, the keys are ordered alphabetically.
+// Module prefixes of the element names are written as present in the path.
+func (p *Path) ToRESTCONF(keyOrder func(*Path) []string) string {
+	sb := strings.Builder{}
+	current := &Path{Origin: p.GetOrigin(), Target: p.GetTarget(), IsRootBased: true}
+	for i, pe := range p.GetElem() {
+		if i > 0 {
+			sb.WriteString("/")
+		}
+		sb.WriteString(pe.GetName())
+		if len(pe.GetKey()) > 0 {
+			var keyNames []string
+			if keyOrder != nil {
+				keyNames = keyOrder(current.CopyPathAddElem(&PathElem{Name: pe.GetName()}))
+			}
+			if !sameKeys(keyNames, pe.GetKey()) {
+				keyNames = slices.Sorted(maps.Keys(pe.GetKey()))
+			}
+			for j, k := range keyNames {
+				if j == 0 {
+					sb.WriteString("=")
+				} else {
+					sb.WriteString(",")
+				}
+				sb.WriteString(restconfEscape(pe.GetKey()[k]))
+			}
+		}
+		current = current.CopyPathAddElem(pe)
+	}
+	return sb.String()
+}
+
+// sameKeys returns true if names holds exactly the keys of the map.
+func sameKeys(names []string, keys map[string]string) bool {
+	if len(names) != len(keys) {
+		return false
+	}
+	for _, n := range names {
+		if _, exists := keys[n]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// restconfEscape percent-encodes all characters of s except the unreserved characters of RFC 3986.
+func restconfEscape(s string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+// ParseRESTCONFPath parses a RFC 8040 api-path, relative to the {+restconf}/data resource, into a root based path.
+// The api-path only carries the key values, the key names can not be derived from the path itself. Hence keyOrder
+// is called with the path up to and including each list element, without its keys, to retrieve the key names in
+// schema order. A leaf-list instance, e.g. "dns-server=10.0.0.1", is represented by the key "." and requires keyOrder
+// to return []string{"."} for the leaf-list, as SchemaKeyOrder does. Module prefixes are kept in the element names.
+func ParseRESTCONFPath(s string, keyOrder func(*Path) []string) (*Path, error) {
+	p := &Path{IsRootBased: true}
+	s = strings.TrimPrefix(s, "/")
+	if s == "" {
+		return p, nil
+	}
+	for _, segment := range strings.Split(s, "/") {
+		rawName, rawKeys, hasKeys := strings.Cut(segment, "=")
+		name, err := url.PathUnescape(rawName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid restconf path segment %q: %w", segment, err)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid restconf path segment %q: empty name", segment)
+		}
+		if !hasKeys {
+			p = p.CopyPathAddElem(&PathElem{Name: name})
+			continue
+		}
+		var keyNames []string
+		if keyOrder != nil {
+			keyNames = keyOrder(p.CopyPathAddElem(&PathElem{Name: name}))
+		}
+		if len(keyNames) == 0 {
+			return nil, fmt.Errorf("invalid restconf path segment %q: %s is neither a list nor a leaf-list", segment, name)
+		}
+		values := strings.Split(rawKeys, ",")
+		if len(values) != len(keyNames) {
+			return nil, fmt.Errorf("invalid restconf path segment %q: %d key values given, list %s has keys %v", segment, len(values), name, keyNames)
+		}
+		keys := make(map[string]string, len(keyNames))
+		for i, k := range keyNames {
+			keys[k], err = url.PathUnescape(values[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid restconf path segment %q: %w", segment, err)
+			}
+		}
+		p = p.CopyPathAddElem(NewPathElem(name, keys))
+	}
+	return p, nil
+}
+
+// SchemaKeyOrder returns a key order function for ToRESTCONF and ParseRESTCONFPath, that takes the key names
+// in the order defined by the schema. Leaf-lists have the single key ".". Lookup errors result in no key names.
+func SchemaKeyOrder(lookup SchemaLookupFunc) func(*Path) []string {
+	return func(p *Path) []string {
+		schema, err := lookup(p)
+		if err != nil {
+			return nil
+		}
+		if schema.GetLeaflist() != nil {
+			return []string{"."}
+		}
+		keyNames := make([]string, 0, len(schema.GetContainer().GetKeys()))
+		for _, k := range schema.GetContainer().GetKeys() {
+			keyNames = append(keyNames, k.GetName())
+		}
+		return keyNames
+	}
+}
@@ -0,0 +1,94 @@
+package sdcpb
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPath_ToRESTCONF(t *testing.T) {
+	keyOrder := SchemaKeyOrder(testSchemaLookup(map[string]*SchemaElem{
+		"/network-instance/protocols/bgp/neighbor": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "neighbor",
+			Keys: []*LeafSchema{{Name: "peer-address"}, {Name: "afi"}},
+		}}},
+		"/network-instance": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "network-instance",
+			Keys: []*LeafSchema{{Name: "name"}},
+		}}},
+		"/system/dns/server": {Schema: &SchemaElem_Leaflist{Leaflist: &LeafListSchema{Name: "server"}}},
+	}))
+
+	tests := []struct {
+		name     string
+		xpath    string
+		keyOrder func(*Path) []string
+		want     string
+	}{
+		{
+			name:     "schema key order",
+			xpath:    "/srl_nokia-network-instance:network-instance[name=default]/protocols/bgp/neighbor[peer-address=fe80::1][afi=ipv6]/peer-as",
+			keyOrder: keyOrder,
+			want:     "srl_nokia-network-instance:network-instance=default/protocols/bgp/neighbor=fe80%3A%3A1,ipv6/peer-as",
+		},
+		{
+			name:  "alphabetical without key order",
+			xpath: "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=fe80::1][afi=ipv6]/peer-as",
+			want:  "network-instance=default/protocols/bgp/neighbor=ipv6,fe80%3A%3A1/peer-as",
+		},
+		{
+			name:     "reserved characters in key values",
+			xpath:    `/network-instance[name='a,b/c=d e']/description`,
+			keyOrder: keyOrder,
+			want:     "network-instance=a%2Cb%2Fc%3Dd%20e/description",
+		},
+		{
+			name:     "leaf-list instance",
+			xpath:    "/system/dns/server[.=10.0.0.1]",
+			keyOrder: keyOrder,
+			want:     "system/dns/server=10.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mustParsePath(t, tt.xpath)
+			got := p.ToRESTCONF(tt.keyOrder)
+			if got != tt.want {
+				t.Fatalf("ToRESTCONF() = %q, want %q", got, tt.want)
+			}
+			if tt.keyOrder == nil {
+				return
+			}
+			parsed, err := ParseRESTCONFPath(got, tt.keyOrder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(parsed, p) {
+				t.Errorf("ParseRESTCONFPath() = %v, want %v", parsed, p)
+			}
+		})
+	}
+}
+
+func TestParseRESTCONFPath_Errors(t *testing.T) {
+	keyOrder := func(p *Path) []string {
+		switch p.LastPathElem().GetName() {
+		case "interface":
+			return []string{"name"}
+		case "server":
+			return []string{"."}
+		}
+		return nil
+	}
+	for _, s := range []string{
+		"interface=a,b/description",
+		"system/dns/server=a,b",
+		"system=a/name",
+		"interface=a%ZZ/description",
+		"interface//description",
+	} {
+		if _, err := ParseRESTCONFPath(s, keyOrder); err == nil {
+			t.Errorf("ParseRESTCONFPath(%q) expected error", s)
+		}
+	}
+}
@@ -1,21 +1,24 @@
 package sdcpb
 
-import "iter"
+import (
+	"iter"
+	"slices"
+)
 
 type PathSet struct {
-	pathMap map[string]*Path
+	paths *PathTrie[struct{}]
 }
 
 func NewPathSet() *PathSet {
 	return &PathSet{
-		pathMap: map[string]*Path{},
+		paths: NewPathTrie[struct{}](),
 	}
 }
 
 func (ps *PathSet) DeepCopy() *PathSet {
 	result := NewPathSet()
-	for k, v := range ps.pathMap {
-		result.pathMap[k] = v.DeepCopy()
+	for p := range ps.Items() {
+		result.paths.Insert(p.DeepCopy(), struct{}{})
 	}
 	return result
 }
@@ -28,24 +31,23 @@ func (ps *PathSet) AddPaths(paths []*Path) *PathSet {
 }
 
 func (ps *PathSet) AddPath(p *Path) *PathSet {
-	key := p.ToXPath(false)
-	if _, exists := ps.pathMap[key]; !exists {
-		ps.pathMap[key] = p
+	if !ps.paths.Contains(p) {
+		ps.paths.Insert(p, struct{}{})
 	}
 	return ps
 }
 
 func (ps *PathSet) Join(otherPs *PathSet) *PathSet {
-	for k, v := range otherPs.pathMap {
-		ps.pathMap[k] = v
+	for p := range otherPs.Items() {
+		ps.paths.Insert(p, struct{}{})
 	}
 	return ps
 }
 
 func (ps *PathSet) Items() iter.Seq[*Path] {
 	return func(yield func(*Path) bool) {
-		for _, v := range ps.pathMap {
-			if !yield(v) {
+		for p := range ps.paths.All() {
+			if !yield(p) {
 				return
 			}
 		}
@@ -53,9 +55,112 @@ func (ps *PathSet) Items() iter.Seq[*Path] {
 }
 
 func (ps *PathSet) ToPathSlice() []*Path {
-	result := make([]*Path, 0, len(ps.pathMap))
+	result := make([]*Path, 0, ps.paths.Len())
 	for p := range ps.Items() {
 		result = append(result, p)
 	}
 	return result
 }
+
+// ContainsParentPath checks if any path in the PathSet is a parent path of the given path.
+func (ps *PathSet) ContainsParentPath(p *Path) bool {
+	return ps.paths.ContainsParentPath(p)
+}
+
+// Len returns the number of paths in the PathSet.
+func (ps *PathSet) Len() int {
+	return ps.paths.Len()
+}
+
+// Contains returns true if the exact path is part of the PathSet.
+func (ps *PathSet) Contains(p *Path) bool {
+	return ps.paths.Contains(p)
+}
+
+// Remove removes the path from the PathSet.
+func (ps *PathSet) Remove(p *Path) *PathSet {
+	ps.paths.Delete(p)
+	return ps
+}
+
+// Intersect removes all paths from the PathSet that are not contained in otherPs.
+func (ps *PathSet) Intersect(otherPs *PathSet) *PathSet {
+	ps.removeIf(func(p *Path) bool {
+		return !otherPs.Contains(p)
+	})
+	return ps
+}
+
+// Difference removes all paths from the PathSet that are contained in otherPs.
+func (ps *PathSet) Difference(otherPs *PathSet) *PathSet {
+	ps.removeIf(otherPs.Contains)
+	return ps
+}
+
+// IsSubsetOf returns true if every path of the PathSet is also contained in otherPs.
+func (ps *PathSet) IsSubsetOf(otherPs *PathSet) bool {
+	if ps.Len() > otherPs.Len() {
+		return false
+	}
+	for p := range ps.Items() {
+		if !otherPs.Contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Minimize removes every path that is already covered by a parent path in the PathSet,
+// following the semantics of IsParentPathOf.
+func (ps *PathSet) Minimize() *PathSet {
+	ps.removeIf(ps.hasStrictParent)
+	return ps
+}
+
+// hasStrictParent returns true if the PathSet contains a parent path of p that is not p itself.
+func (ps *PathSet) hasStrictParent(p *Path) bool {
+	elems := p.GetElem()
+	if len(elems) == 0 {
+		return false
+	}
+	parent := &Path{
+		Origin:      p.GetOrigin(),
+		Target:      p.GetTarget(),
+		IsRootBased: p.GetIsRootBased(),
+		Elem:        elems[: len(elems)-1 : len(elems)-1],
+	}
+	if ps.ContainsParentPath(parent) {
+		return true
+	}
+	// a keyless entry of the last element covers all its keyed instances
+	last := elems[len(elems)-1]
+	if len(last.GetKey()) == 0 {
+		return false
+	}
+	return ps.Contains(parent.CopyPathAddElem(&PathElem{Name: last.GetName()}))
+}
+
+func (ps *PathSet) removeIf(f func(p *Path) bool) {
+	remove := []*Path{}
+	for p := range ps.Items() {
+		if f(p) {
+			remove = append(remove, p)
+		}
+	}
+	for _, p := range remove {
+		ps.paths.Delete(p)
+	}
+}
+
+// SortedItems iterates the paths of the PathSet in the order defined by ComparePath.
+func (ps *PathSet) SortedItems() iter.Seq[*Path] {
+	return func(yield func(*Path) bool) {
+		sorted := ps.ToPathSlice()
+		slices.SortFunc(sorted, ComparePath)
+		for _, p := range sorted {
+			if !yield(p) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTestPathSet(t *testing.T, paths ...string) *PathSet {
+	t.Helper()
+	ps := NewPathSet()
+	for _, s := range paths {
+		ps.AddPath(mustParsePath(t, s))
+	}
+	return ps
+}
+
+func sortedXPaths(ps *PathSet) []string {
+	result := []string{}
+	for p := range ps.SortedItems() {
+		result = append(result, p.ToXPath(false))
+	}
+	return result
+}
+
+func TestPathSet_SetOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []string
+		b    []string
+		op   func(a, b *PathSet) *PathSet
+		want []string
+	}{
+		{
+			name: "Intersect",
+			a:    []string{"/a/b", "/a/c", "/x[k=1]"},
+			b:    []string{"/a/c", "/x[k=1]", "/x[k=2]"},
+			op:   (*PathSet).Intersect,
+			want: []string{"/a/c", "/x[k=1]"},
+		},
+		{
+			name: "Difference",
+			a:    []string{"/a/b", "/a/c", "/x[k=1]"},
+			b:    []string{"/a/c", "/x[k=1]", "/x[k=2]"},
+			op:   (*PathSet).Difference,
+			want: []string{"/a/b"},
+		},
+		{
+			name: "Join",
+			a:    []string{"/a/b"},
+			b:    []string{"/a/c", "/a/b"},
+			op:   (*PathSet).Join,
+			want: []string{"/a/b", "/a/c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sortedXPaths(tt.op(newTestPathSet(t, tt.a...), newTestPathSet(t, tt.b...)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathSet_ContainsRemoveLen(t *testing.T) {
+	ps := newTestPathSet(t, "/a/b", "/a/c", "/a/b")
+	if ps.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", ps.Len())
+	}
+	if !ps.Contains(mustParsePath(t, "/a/b")) {
+		t.Errorf("Contains() = false, want true")
+	}
+	ps.Remove(mustParsePath(t, "/a/b"))
+	if ps.Contains(mustParsePath(t, "/a/b")) || ps.Len() != 1 {
+		t.Errorf("Remove() did not remove the path")
+	}
+}
+
+func TestPathSet_IsSubsetOf(t *testing.T) {
+	a := newTestPathSet(t, "/a/b", "/x[k=1]")
+	b := newTestPathSet(t, "/a/b", "/x[k=1]", "/x[k=2]")
+	if !a.IsSubsetOf(b) {
+		t.Errorf("a.IsSubsetOf(b) = false, want true")
+	}
+	if b.IsSubsetOf(a) {
+		t.Errorf("b.IsSubsetOf(a) = true, want false")
+	}
+	if !NewPathSet().IsSubsetOf(a) {
+		t.Errorf("empty set must be a subset of any set")
+	}
+}
+
+func TestPathSet_Minimize(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "children of a parent are dropped",
+			paths: []string{"/a", "/a/b", "/a/b/c", "/d/e"},
+			want:  []string{"/a", "/d/e"},
+		},
+		{
+			name:  "keyless list covers list entries",
+			paths: []string{"/x", "/x[k=1]", "/x[k=2]/y", "/z[k=1]"},
+			want:  []string{"/x", "/z[k=1]"},
+		},
+		{
+			name:  "list entry does not cover siblings",
+			paths: []string{"/x[k=1]", "/x[k=1]/y", "/x[k=2]/y"},
+			want:  []string{"/x[k=1]", "/x[k=2]/y"},
+		},
+		{
+			name:  "root covers everything",
+			paths: []string{"/", "/a", "/b[k=1]"},
+			want:  []string{"/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sortedXPaths(newTestPathSet(t, tt.paths...).Minimize())
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Minimize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathSet_SortedItems(t *testing.T) {
+	ps := newTestPathSet(t, "/c", "/a/b", "/b[k=2]", "/a", "/b[k=1]")
+	want := []string{"/a", "/a/b", "/b[k=1]", "/b[k=2]", "/c"}
+	for range 10 {
+		if got := sortedXPaths(ps); !slices.Equal(got, want) {
+			t.Fatalf("SortedItems() = %v, want %v", got, want)
+		}
+	}
+}
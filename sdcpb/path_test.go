@@ -15,7 +15,11 @@
 package sdcpb
 
 import (
+	"maps"
+	"math/rand/v2"
 	"testing"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func TestStripPathElemPrefix(t *testing.T) {
@@ -267,3 +271,81 @@ func TestToXPath(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePath_QuotedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		xpath   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "single quoted with slash", xpath: "/interface[name='eth/1']/mtu", want: map[string]string{"name": "eth/1"}},
+		{name: "double quoted with bracket", xpath: `/interface[name="a]b"]/mtu`, want: map[string]string{"name": "a]b"}},
+		{name: "escaped quote", xpath: `/interface[name='it\'s']/mtu`, want: map[string]string{"name": "it's"}},
+		{name: "empty quoted", xpath: "/interface[name='']/mtu", want: map[string]string{"name": ""}},
+		{name: "blanks preserved", xpath: "/interface[name=' a ']/mtu", want: map[string]string{"name": " a "}},
+		{name: "quote within unquoted value", xpath: "/interface[name=it's]/mtu", want: map[string]string{"name": "it's"}},
+		{name: "legacy escaped brackets", xpath: `/interface[name=a\[1\]]/mtu`, want: map[string]string{"name": "a[1]"}},
+		{name: "unterminated quote", xpath: "/interface[name='eth/1]/mtu", wantErr: true},
+		{name: "characters after closing quote", xpath: "/interface[name='a'b]/mtu", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePath(tt.xpath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(p.GetElem()) != 2 {
+				t.Fatalf("ParsePath() = %v, want 2 elements", p)
+			}
+			if got := p.GetElem()[0].GetKey(); !maps.Equal(got, tt.want) {
+				t.Errorf("ParsePath() keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToXPath_ParsePathRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	nameChars := []rune("abcxyz-_.0123")
+	valueChars := []rune("ab/=[]'\"\\ \tä:.*")
+	randString := func(chars []rune, minLen int) string {
+		r := make([]rune, minLen+rnd.IntN(6))
+		for i := range r {
+			r[i] = chars[rnd.IntN(len(chars))]
+		}
+		return string(r)
+	}
+	randName := func() string {
+		name := string(rune('a'+rnd.IntN(26))) + randString(nameChars, 0)
+		if rnd.IntN(3) == 0 {
+			name = "mod:" + name
+		}
+		return name
+	}
+
+	for i := 0; i < 5000; i++ {
+		p := &Path{IsRootBased: rnd.IntN(2) == 0}
+		for range rnd.IntN(4) {
+			pe := &PathElem{Name: randName()}
+			for range rnd.IntN(3) {
+				if pe.Key == nil {
+					pe.Key = map[string]string{}
+				}
+				pe.Key[randName()] = randString(valueChars, 0)
+			}
+			p.Elem = append(p.Elem, pe)
+		}
+		xpath := p.ToXPath(false)
+		got, err := ParsePath(xpath)
+		if err != nil {
+			t.Fatalf("ParsePath(%q) failed: %v", xpath, err)
+		}
+		if !proto.Equal(got, p) {
+			t.Fatalf("ParsePath(%q) = %v, want %v", xpath, got, p)
+		}
+	}
+}
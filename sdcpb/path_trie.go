@@ -0,0 +1,375 @@
+package sdcpb
+
+import (
+	"iter"
+	"slices"
+)
+
+// PathTrie is an index of paths and associated values. Each PathElem of a path is stored as one level
+// in the trie, keyed on the name and the hash of the element, such that prefix and containment
+// queries do not need to compare all the stored paths.
+// Paths with different origin, target or root-basedness are stored in separate sub-tries.
+// The trie stores the provided *Path references, callers must not modify paths after inserting them.
+type PathTrie[T any] struct {
+	roots map[pathTrieHeader]*pathTrieNode[T]
+	size  int
+}
+
+type pathTrieHeader struct {
+	origin      string
+	target      string
+	isRootBased bool
+}
+
+type pathTrieNode[T any] struct {
+	// elem is the PathElem that leads to this node, nil for the root nodes
+	elem *PathElem
+	// children is indexed by PathElem name first and then by the PathElem hash, colliding elements share a slot
+	children map[string]map[uint64][]*pathTrieNode[T]
+	path     *Path
+	value    T
+	hasValue bool
+}
+
+func NewPathTrie[T any]() *PathTrie[T] {
+	return &PathTrie[T]{
+		roots: map[pathTrieHeader]*pathTrieNode[T]{},
+	}
+}
+
+func newPathTrieHeader(p *Path) pathTrieHeader {
+	return pathTrieHeader{
+		origin:      p.GetOrigin(),
+		target:      p.GetTarget(),
+		isRootBased: p.GetIsRootBased(),
+	}
+}
+
+// keylessPathElemHash returns the hash of a PathElem with the given name and no keys, see PathElem.Hash.
+func keylessPathElemHash(name string) uint64 {
+	return hashUint64(hashUint64(hashString(fnvOffset64, name), 0), 0)
+}
+
+func (n *pathTrieNode[T]) child(pe *PathElem) *pathTrieNode[T] {
+	for _, c := range n.children[pe.GetName()][pe.Hash()] {
+		if pathElemEqual(c.elem, pe) {
+			return c
+		}
+	}
+	return nil
+}
+
+// keylessChild returns the child with the given name and no keys.
+func (n *pathTrieNode[T]) keylessChild(name string) *pathTrieNode[T] {
+	for _, c := range n.children[name][keylessPathElemHash(name)] {
+		if len(c.elem.GetKey()) == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *pathTrieNode[T]) addChild(pe *PathElem) *pathTrieNode[T] {
+	if c := n.child(pe); c != nil {
+		return c
+	}
+	if n.children == nil {
+		n.children = map[string]map[uint64][]*pathTrieNode[T]{}
+	}
+	byHash, exists := n.children[pe.GetName()]
+	if !exists {
+		byHash = map[uint64][]*pathTrieNode[T]{}
+		n.children[pe.GetName()] = byHash
+	}
+	c := &pathTrieNode[T]{elem: pe}
+	h := pe.Hash()
+	byHash[h] = append(byHash[h], c)
+	return c
+}
+
+// removeChild removes the child node c, which was added for the PathElem pe.
+func (n *pathTrieNode[T]) removeChild(pe *PathElem, c *pathTrieNode[T]) {
+	byHash := n.children[pe.GetName()]
+	h := pe.Hash()
+	byHash[h] = slices.DeleteFunc(byHash[h], func(e *pathTrieNode[T]) bool { return e == c })
+	if len(byHash[h]) == 0 {
+		delete(byHash, h)
+	}
+	if len(byHash) == 0 {
+		delete(n.children, pe.GetName())
+	}
+}
+
+// eachChild iterates the child nodes of one name, including colliding ones.
+func eachChild[T any](byHash map[uint64][]*pathTrieNode[T]) iter.Seq[*pathTrieNode[T]] {
+	return func(yield func(*pathTrieNode[T]) bool) {
+		for _, nodes := range byHash {
+			for _, c := range nodes {
+				if !yield(c) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// isEmpty returns true if the node neither holds a value nor has any children.
+func (n *pathTrieNode[T]) isEmpty() bool {
+	return !n.hasValue && len(n.children) == 0
+}
+
+// lookup returns the node of the given path, if create is set missing nodes are created.
+func (t *PathTrie[T]) lookup(p *Path, create bool) *pathTrieNode[T] {
+	h := newPathTrieHeader(p)
+	n, exists := t.roots[h]
+	if !exists {
+		if !create {
+			return nil
+		}
+		n = &pathTrieNode[T]{}
+		t.roots[h] = n
+	}
+	for _, pe := range p.GetElem() {
+		var c *pathTrieNode[T]
+		if create {
+			c = n.addChild(pe)
+		} else {
+			c = n.child(pe)
+		}
+		if c == nil {
+			return nil
+		}
+		n = c
+	}
+	return n
+}
+
+// Len returns the number of paths stored in the trie.
+func (t *PathTrie[T]) Len() int {
+	return t.size
+}
+
+// Insert stores the value under the given path, replacing any previous value.
+// It returns true if the path was not yet present in the trie.
+func (t *PathTrie[T]) Insert(p *Path, value T) bool {
+	n := t.lookup(p, true)
+	added := !n.hasValue
+	if added {
+		t.size++
+	}
+	n.path = p
+	n.value = value
+	n.hasValue = true
+	return added
+}
+
+// Get returns the value stored for the exact path.
+func (t *PathTrie[T]) Get(p *Path) (T, bool) {
+	n := t.lookup(p, false)
+	if n == nil || !n.hasValue {
+		var zero T
+		return zero, false
+	}
+	return n.value, true
+}
+
+// Contains returns true if the exact path is stored in the trie.
+func (t *PathTrie[T]) Contains(p *Path) bool {
+	_, ok := t.Get(p)
+	return ok
+}
+
+// Delete removes the exact path from the trie and returns true if it was present.
+// Nodes that do no longer lead to any value are pruned.
+func (t *PathTrie[T]) Delete(p *Path) bool {
+	h := newPathTrieHeader(p)
+	root, exists := t.roots[h]
+	if !exists {
+		return false
+	}
+	deleted := root.delete(p.GetElem())
+	if deleted {
+		t.size--
+		if root.isEmpty() {
+			delete(t.roots, h)
+		}
+	}
+	return deleted
+}
+
+func (n *pathTrieNode[T]) delete(elems []*PathElem) bool {
+	if len(elems) == 0 {
+		if !n.hasValue {
+			return false
+		}
+		var zero T
+		n.value = zero
+		n.path = nil
+		n.hasValue = false
+		return true
+	}
+	c := n.child(elems[0])
+	if c == nil || !c.delete(elems[1:]) {
+		return false
+	}
+	if c.isEmpty() {
+		n.removeChild(elems[0], c)
+	}
+	return true
+}
+
+// LongestPrefix returns the longest stored path that is a parent path of (or equal to) p, following
+// the semantics of IsParentPathOf, where a stored last element without keys matches any keys.
+func (t *PathTrie[T]) LongestPrefix(p *Path) (*Path, T, bool) {
+	var result *pathTrieNode[T]
+	n := t.roots[newPathTrieHeader(p)]
+	for _, pe := range p.GetElem() {
+		if n == nil {
+			break
+		}
+		if n.hasValue {
+			result = n
+		}
+		// a stored keyless element is a parent of any keyed instance with the same name
+		if len(pe.GetKey()) > 0 {
+			if c := n.keylessChild(pe.GetName()); c != nil && c.hasValue {
+				result = c
+			}
+		}
+		n = n.child(pe)
+	}
+	if n != nil && n.hasValue {
+		result = n
+	}
+	if result == nil {
+		var zero T
+		return nil, zero, false
+	}
+	return result.path, result.value, true
+}
+
+// ContainsParentPath returns true if any stored path is a parent path of (or equal to) p.
+func (t *PathTrie[T]) ContainsParentPath(p *Path) bool {
+	_, _, ok := t.LongestPrefix(p)
+	return ok
+}
+
+// All iterates all stored paths and their values in no particular order.
+func (t *PathTrie[T]) All() iter.Seq2[*Path, T] {
+	return func(yield func(*Path, T) bool) {
+		for _, root := range t.roots {
+			if !root.walk(yield) {
+				return
+			}
+		}
+	}
+}
+
+func (n *pathTrieNode[T]) walk(yield func(*Path, T) bool) bool {
+	if n.hasValue && !yield(n.path, n.value) {
+		return false
+	}
+	for _, byHash := range n.children {
+		for c := range eachChild(byHash) {
+			if !c.walk(yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Descendants iterates all stored paths that p is a parent path of (including p itself), following
+// the semantics of IsParentPathOf, where a last element of p without keys matches any keys.
+func (t *PathTrie[T]) Descendants(p *Path) iter.Seq2[*Path, T] {
+	return func(yield func(*Path, T) bool) {
+		n := t.roots[newPathTrieHeader(p)]
+		elems := p.GetElem()
+		if n == nil {
+			return
+		}
+		if len(elems) == 0 {
+			n.walk(yield)
+			return
+		}
+		for _, pe := range elems[:len(elems)-1] {
+			n = n.child(pe)
+			if n == nil {
+				return
+			}
+		}
+		last := elems[len(elems)-1]
+		if len(last.GetKey()) > 0 {
+			if c := n.child(last); c != nil {
+				c.walk(yield)
+			}
+			return
+		}
+		for c := range eachChild(n.children[last.GetName()]) {
+			if !c.walk(yield) {
+				return
+			}
+		}
+	}
+}
+
+// Match iterates all stored paths that are matched by the given pattern.
+func (t *PathTrie[T]) Match(pp *PathPattern) iter.Seq2[*Path, T] {
+	return func(yield func(*Path, T) bool) {
+		seen := map[*pathTrieNode[T]]struct{}{}
+		for h, root := range t.roots {
+			if (pp.origin != PathWildcard && pp.origin != h.origin) ||
+				(pp.target != PathWildcard && pp.target != h.target) ||
+				pp.isRootBased != h.isRootBased {
+				continue
+			}
+			if !root.match(pp.elems, seen, yield) {
+				return
+			}
+		}
+	}
+}
+
+func (n *pathTrieNode[T]) match(elems []patternElem, seen map[*pathTrieNode[T]]struct{}, yield func(*Path, T) bool) bool {
+	if len(elems) == 0 {
+		if !n.hasValue {
+			return true
+		}
+		// multi level wildcards might lead to the same node multiple times
+		if _, exists := seen[n]; exists {
+			return true
+		}
+		seen[n] = struct{}{}
+		return yield(n.path, n.value)
+	}
+	e := elems[0]
+	if e.multiLevel {
+		// the wildcard matches zero elements
+		if !n.match(elems[1:], seen, yield) {
+			return false
+		}
+		// or consumes one more element and remains active
+		for _, byHash := range n.children {
+			for c := range eachChild(byHash) {
+				if !c.match(elems, seen, yield) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for name, byHash := range n.children {
+		if !e.anyName && e.name != name {
+			continue
+		}
+		for c := range eachChild(byHash) {
+			if !e.matchElem(c.elem) {
+				continue
+			}
+			if !c.match(elems[1:], seen, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
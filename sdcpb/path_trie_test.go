@@ -0,0 +1,284 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func mustParsePath(t *testing.T, s string) *Path {
+	t.Helper()
+	p, err := ParsePath(s)
+	if err != nil {
+		t.Fatalf("failed parsing path %q: %v", s, err)
+	}
+	return p
+}
+
+func newTestPathTrie(t *testing.T, paths ...string) *PathTrie[string] {
+	t.Helper()
+	trie := NewPathTrie[string]()
+	for _, s := range paths {
+		trie.Insert(mustParsePath(t, s), s)
+	}
+	return trie
+}
+
+func collectTrieValues(seq func(yield func(*Path, string) bool)) []string {
+	result := []string{}
+	for _, v := range seq {
+		result = append(result, v)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func TestPathTrie_InsertGetDelete(t *testing.T) {
+	trie := newTestPathTrie(t,
+		"/interface[name=ethernet-1/1]/admin-state",
+		"/interface[name=ethernet-1/1]/description",
+		"/interface[name=ethernet-1/2]",
+	)
+	if trie.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", trie.Len())
+	}
+	if trie.Insert(mustParsePath(t, "/interface[name=ethernet-1/2]"), "replaced") {
+		t.Errorf("Insert() of existing path reported a new path")
+	}
+	if v, ok := trie.Get(mustParsePath(t, "/interface[name=ethernet-1/2]")); !ok || v != "replaced" {
+		t.Errorf("Get() = %q, %v, want %q, true", v, ok, "replaced")
+	}
+	if trie.Contains(mustParsePath(t, "/interface[name=ethernet-1/1]")) {
+		t.Errorf("Contains() returned true for intermediate node")
+	}
+	if trie.Contains(mustParsePath(t, "interface[name=ethernet-1/2]")) {
+		t.Errorf("Contains() returned true for relative path")
+	}
+	if !trie.Delete(mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state")) {
+		t.Errorf("Delete() of existing path returned false")
+	}
+	if trie.Delete(mustParsePath(t, "/interface[name=ethernet-1/1]/admin-state")) {
+		t.Errorf("Delete() of deleted path returned true")
+	}
+	if trie.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", trie.Len())
+	}
+	trie.Delete(mustParsePath(t, "/interface[name=ethernet-1/1]/description"))
+	trie.Delete(mustParsePath(t, "/interface[name=ethernet-1/2]"))
+	if trie.Len() != 0 || len(trie.roots) != 0 {
+		t.Errorf("expected empty and pruned trie, got Len() = %d, roots = %d", trie.Len(), len(trie.roots))
+	}
+}
+
+func TestPathTrie_HashCollision(t *testing.T) {
+	a := &PathElem{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}}
+	b := &PathElem{Name: "interface", Key: map[string]string{"name": "ethernet-1/2"}}
+	n := &pathTrieNode[string]{}
+	ca := n.addChild(a)
+	// simulate a hash collision by placing the node of b in front of a in the slot of a
+	cb := &pathTrieNode[string]{elem: b}
+	h := a.Hash()
+	n.children["interface"][h] = append([]*pathTrieNode[string]{cb}, n.children["interface"][h]...)
+	if c := n.child(a); c != ca {
+		t.Errorf("child() returned the colliding node")
+	}
+	if c := n.addChild(a); c != ca {
+		t.Errorf("addChild() did not return the existing node")
+	}
+	if c := n.child(&PathElem{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}}); c != ca {
+		t.Errorf("child() did not find the node of an equal element")
+	}
+	n.removeChild(a, ca)
+	if got := n.children["interface"][h]; len(got) != 1 || got[0] != cb {
+		t.Errorf("removeChild() removed the colliding node")
+	}
+}
+
+func TestPathTrie_LongestPrefix(t *testing.T) {
+	trie := newTestPathTrie(t,
+		"/interface",
+		"/interface[name=ethernet-1/1]",
+		"/interface[name=ethernet-1/1]/subinterface[index=0]",
+		"/network-instance",
+	)
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "exact match",
+			path:   "/interface[name=ethernet-1/1]/subinterface[index=0]",
+			want:   "/interface[name=ethernet-1/1]/subinterface[index=0]",
+			wantOk: true,
+		},
+		{
+			name:   "deepest parent",
+			path:   "/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+			want:   "/interface[name=ethernet-1/1]/subinterface[index=0]",
+			wantOk: true,
+		},
+		{
+			name:   "keyless element matches other keys",
+			path:   "/interface[name=ethernet-1/2]/admin-state",
+			want:   "/interface",
+			wantOk: true,
+		},
+		{
+			name:   "keyless element matches keyed instance",
+			path:   "/network-instance[name=default]/protocols",
+			want:   "/network-instance",
+			wantOk: true,
+		},
+		{
+			name:   "no parent",
+			path:   "/system/name",
+			wantOk: false,
+		},
+		{
+			name:   "different root-basedness",
+			path:   "interface[name=ethernet-1/1]",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, ok := trie.LongestPrefix(mustParsePath(t, tt.path))
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("LongestPrefix() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+			paths := Paths{}
+			for p := range trie.All() {
+				paths = append(paths, p)
+			}
+			if paths.ContainsParentPath(mustParsePath(t, tt.path)) != trie.ContainsParentPath(mustParsePath(t, tt.path)) {
+				t.Errorf("ContainsParentPath() differs from Paths.ContainsParentPath()")
+			}
+		})
+	}
+}
+
+func TestPathTrie_Descendants(t *testing.T) {
+	trie := newTestPathTrie(t,
+		"/interface[name=ethernet-1/1]/admin-state",
+		"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+		"/interface[name=ethernet-1/2]/admin-state",
+		"/system/name",
+	)
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{
+			name: "keyed list entry",
+			path: "/interface[name=ethernet-1/1]",
+			want: []string{
+				"/interface[name=ethernet-1/1]/admin-state",
+				"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+			},
+		},
+		{
+			name: "keyless list matches all entries",
+			path: "/interface",
+			want: []string{
+				"/interface[name=ethernet-1/1]/admin-state",
+				"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+				"/interface[name=ethernet-1/2]/admin-state",
+			},
+		},
+		{
+			name: "root",
+			path: "/",
+			want: []string{
+				"/interface[name=ethernet-1/1]/admin-state",
+				"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+				"/interface[name=ethernet-1/2]/admin-state",
+				"/system/name",
+			},
+		},
+		{
+			name: "no descendants",
+			path: "/interface[name=ethernet-1/3]",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collectTrieValues(trie.Descendants(mustParsePath(t, tt.path)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Descendants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathTrie_Match(t *testing.T) {
+	trie := newTestPathTrie(t,
+		"/interface[name=ethernet-1/1]/admin-state",
+		"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+		"/interface[name=ethernet-1/2]/admin-state",
+		"/interface[name=ethernet-1/2]/description",
+		"/system/name",
+	)
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "key wildcard",
+			pattern: "/interface[name=*]/admin-state",
+			want: []string{
+				"/interface[name=ethernet-1/1]/admin-state",
+				"/interface[name=ethernet-1/2]/admin-state",
+			},
+		},
+		{
+			name:    "element wildcard",
+			pattern: "/interface[name=ethernet-1/2]/*",
+			want: []string{
+				"/interface[name=ethernet-1/2]/admin-state",
+				"/interface[name=ethernet-1/2]/description",
+			},
+		},
+		{
+			name:    "multi level wildcard",
+			pattern: "/.../admin-state",
+			want: []string{
+				"/interface[name=ethernet-1/1]/admin-state",
+				"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+				"/interface[name=ethernet-1/2]/admin-state",
+			},
+		},
+		{
+			name:    "multi level wildcards yield each path once",
+			pattern: "/.../*/...",
+			want: []string{
+				"/interface[name=ethernet-1/1]/admin-state",
+				"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state",
+				"/interface[name=ethernet-1/2]/admin-state",
+				"/interface[name=ethernet-1/2]/description",
+				"/system/name",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pp, err := ParsePathPattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := collectTrieValues(trie.Match(pp))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			// cross check with the pattern matcher
+			for p, v := range trie.All() {
+				if pp.Matches(p) != slices.Contains(got, v) {
+					t.Errorf("Match() and Matches() disagree on %s", v)
+				}
+			}
+		})
+	}
+}
@@ -2071,8 +2071,15 @@ type SchemaLeafType struct {
 	ModulePrefixMap     map[string]string `protobuf:"bytes,12,rep,name=module_prefix_map,json=modulePrefixMap,proto3" json:"module_prefix_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	LeafrefTargetType   *SchemaLeafType   `protobuf:"bytes,13,opt,name=leafref_target_type,json=leafrefTargetType,proto3" json:"leafref_target_type,omitempty"`
 	Bits                []*Bit            `protobuf:"bytes,14,rep,name=bits,proto3" json:"bits,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+	// enum_values maps the enum names of an enumeration type to their assigned
+	// values.
+	// https://datatracker.ietf.org/doc/html/rfc7950#section-9.6.4.2
+	EnumValues map[string]int32 `protobuf:"bytes,15,rep,name=enum_values,json=enumValues,proto3" json:"enum_values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// fraction_digits defines the number of digits following the decimal point
+	// of a decimal64 type.
+	FractionDigits uint32 `protobuf:"varint,16,opt,name=fraction_digits,json=fractionDigits,proto3" json:"fraction_digits,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SchemaLeafType) Reset() {
@@ -2203,6 +2210,20 @@ func (x *SchemaLeafType) GetBits() []*Bit {
 	return nil
 }
 
+func (x *SchemaLeafType) GetEnumValues() map[string]int32 {
+	if x != nil {
+		return x.EnumValues
+	}
+	return nil
+}
+
+func (x *SchemaLeafType) GetFractionDigits() uint32 {
+	if x != nil {
+		return x.FractionDigits
+	}
+	return 0
+}
+
 type MustStatement struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Statement     string                 `protobuf:"bytes,1,opt,name=statement,proto3" json:"statement,omitempty"`
@@ -2482,9 +2503,12 @@ func (x *SchemaMinMaxType) GetMax() *Number {
 }
 
 type Number struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Value         uint64                 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
-	Negative      bool                   `protobuf:"varint,2,opt,name=negative,proto3" json:"negative,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Value    uint64                 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	Negative bool                   `protobuf:"varint,2,opt,name=negative,proto3" json:"negative,omitempty"`
+	// precision defines the number of digits of value following the decimal
+	// point, it is used for the range boundaries of decimal64 types.
+	Precision     uint32 `protobuf:"varint,3,opt,name=precision,proto3" json:"precision,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -2533,6 +2557,13 @@ func (x *Number) GetNegative() bool {
 	return false
 }
 
+func (x *Number) GetPrecision() uint32 {
+	if x != nil {
+		return x.Precision
+	}
+	return 0
+}
+
 type Bit struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -2878,7 +2909,7 @@ const file_schema_proto_rawDesc = "" +
 	"\bis_state\x18\x15 \x01(\bR\aisState\x12\x1d\n" +
 	"\n" +
 	"if_feature\x18\x17 \x03(\tR\tifFeature\x12\x1c\n" +
-	"\treference\x18\x19 \x03(\tR\treference\"\xbe\x06\n" +
+	"\treference\x18\x19 \x03(\tR\treference\"\xef\a\n" +
 	"\x0eSchemaLeafType\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12.\n" +
 	"\x05range\x18\x02 \x03(\v2\x18.schema.SchemaMinMaxTypeR\x05range\x120\n" +
@@ -2896,13 +2927,19 @@ const file_schema_proto_rawDesc = "" +
 	"\x15identity_prefixes_map\x18\v \x03(\v2/.schema.SchemaLeafType.IdentityPrefixesMapEntryR\x13identityPrefixesMap\x12W\n" +
 	"\x11module_prefix_map\x18\f \x03(\v2+.schema.SchemaLeafType.ModulePrefixMapEntryR\x0fmodulePrefixMap\x12F\n" +
 	"\x13leafref_target_type\x18\r \x01(\v2\x16.schema.SchemaLeafTypeR\x11leafrefTargetType\x12\x1f\n" +
-	"\x04bits\x18\x0e \x03(\v2\v.schema.BitR\x04bits\x1aF\n" +
+	"\x04bits\x18\x0e \x03(\v2\v.schema.BitR\x04bits\x12G\n" +
+	"\venum_values\x18\x0f \x03(\v2&.schema.SchemaLeafType.EnumValuesEntryR\n" +
+	"enumValues\x12'\n" +
+	"\x0ffraction_digits\x18\x10 \x01(\rR\x0efractionDigits\x1aF\n" +
 	"\x18IdentityPrefixesMapEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aB\n" +
 	"\x14ModulePrefixMapEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a=\n" +
+	"\x0fEnumValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"C\n" +
 	"\rMustStatement\x12\x1c\n" +
 	"\tstatement\x18\x01 \x01(\tR\tstatement\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"\x83\x01\n" +
@@ -2922,10 +2959,11 @@ const file_schema_proto_rawDesc = "" +
 	"\binverted\x18\x02 \x01(\bR\binverted\"V\n" +
 	"\x10SchemaMinMaxType\x12 \n" +
 	"\x03min\x18\x01 \x01(\v2\x0e.schema.NumberR\x03min\x12 \n" +
-	"\x03max\x18\x02 \x01(\v2\x0e.schema.NumberR\x03max\":\n" +
+	"\x03max\x18\x02 \x01(\v2\x0e.schema.NumberR\x03max\"X\n" +
 	"\x06Number\x12\x14\n" +
 	"\x05value\x18\x01 \x01(\x04R\x05value\x12\x1a\n" +
-	"\bnegative\x18\x02 \x01(\bR\bnegative\"5\n" +
+	"\bnegative\x18\x02 \x01(\bR\bnegative\x12\x1c\n" +
+	"\tprecision\x18\x03 \x01(\rR\tprecision\"5\n" +
 	"\x03Bit\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\bposition\x18\x02 \x01(\rR\bposition\"\x99\x01\n" +
@@ -2981,7 +3019,7 @@ func file_schema_proto_rawDescGZIP() []byte {
 }
 
 var file_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
+var file_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
 var file_schema_proto_goTypes = []any{
 	(SchemaStatus)(0),                // 0: schema.SchemaStatus
 	(DataType)(0),                    // 1: schema.DataType
@@ -3027,9 +3065,10 @@ var file_schema_proto_goTypes = []any{
 	(*ChoiceCase)(nil),               // 41: schema.ChoiceCase
 	nil,                              // 42: schema.SchemaLeafType.IdentityPrefixesMapEntry
 	nil,                              // 43: schema.SchemaLeafType.ModulePrefixMapEntry
-	nil,                              // 44: schema.PathElem.KeyEntry
-	nil,                              // 45: schema.ChoiceInfo.ChoiceEntry
-	nil,                              // 46: schema.ChoiceInfoChoice.CaseEntry
+	nil,                              // 44: schema.SchemaLeafType.EnumValuesEntry
+	nil,                              // 45: schema.PathElem.KeyEntry
+	nil,                              // 46: schema.ChoiceInfo.ChoiceEntry
+	nil,                              // 47: schema.ChoiceInfoChoice.CaseEntry
 }
 var file_schema_proto_depIdxs = []int32{
 	0,  // 0: schema.Schema.status:type_name -> schema.SchemaStatus
@@ -3076,39 +3115,40 @@ var file_schema_proto_depIdxs = []int32{
 	43, // 41: schema.SchemaLeafType.module_prefix_map:type_name -> schema.SchemaLeafType.ModulePrefixMapEntry
 	31, // 42: schema.SchemaLeafType.leafref_target_type:type_name -> schema.SchemaLeafType
 	38, // 43: schema.SchemaLeafType.bits:type_name -> schema.Bit
-	44, // 44: schema.PathElem.key:type_name -> schema.PathElem.KeyEntry
-	33, // 45: schema.Path.elem:type_name -> schema.PathElem
-	37, // 46: schema.SchemaMinMaxType.min:type_name -> schema.Number
-	37, // 47: schema.SchemaMinMaxType.max:type_name -> schema.Number
-	45, // 48: schema.ChoiceInfo.choice:type_name -> schema.ChoiceInfo.ChoiceEntry
-	46, // 49: schema.ChoiceInfoChoice.case:type_name -> schema.ChoiceInfoChoice.CaseEntry
-	40, // 50: schema.ChoiceInfo.ChoiceEntry.value:type_name -> schema.ChoiceInfoChoice
-	41, // 51: schema.ChoiceInfoChoice.CaseEntry.value:type_name -> schema.ChoiceCase
-	5,  // 52: schema.SchemaServer.GetSchemaDetails:input_type -> schema.GetSchemaDetailsRequest
-	7,  // 53: schema.SchemaServer.ListSchema:input_type -> schema.ListSchemaRequest
-	9,  // 54: schema.SchemaServer.GetSchema:input_type -> schema.GetSchemaRequest
-	12, // 55: schema.SchemaServer.CreateSchema:input_type -> schema.CreateSchemaRequest
-	14, // 56: schema.SchemaServer.ReloadSchema:input_type -> schema.ReloadSchemaRequest
-	16, // 57: schema.SchemaServer.DeleteSchema:input_type -> schema.DeleteSchemaRequest
-	18, // 58: schema.SchemaServer.UploadSchema:input_type -> schema.UploadSchemaRequest
-	20, // 59: schema.SchemaServer.ToPath:input_type -> schema.ToPathRequest
-	22, // 60: schema.SchemaServer.ExpandPath:input_type -> schema.ExpandPathRequest
-	9,  // 61: schema.SchemaServer.GetSchemaElements:input_type -> schema.GetSchemaRequest
-	6,  // 62: schema.SchemaServer.GetSchemaDetails:output_type -> schema.GetSchemaDetailsResponse
-	8,  // 63: schema.SchemaServer.ListSchema:output_type -> schema.ListSchemaResponse
-	10, // 64: schema.SchemaServer.GetSchema:output_type -> schema.GetSchemaResponse
-	13, // 65: schema.SchemaServer.CreateSchema:output_type -> schema.CreateSchemaResponse
-	15, // 66: schema.SchemaServer.ReloadSchema:output_type -> schema.ReloadSchemaResponse
-	17, // 67: schema.SchemaServer.DeleteSchema:output_type -> schema.DeleteSchemaResponse
-	26, // 68: schema.SchemaServer.UploadSchema:output_type -> schema.UploadSchemaResponse
-	21, // 69: schema.SchemaServer.ToPath:output_type -> schema.ToPathResponse
-	23, // 70: schema.SchemaServer.ExpandPath:output_type -> schema.ExpandPathResponse
-	10, // 71: schema.SchemaServer.GetSchemaElements:output_type -> schema.GetSchemaResponse
-	62, // [62:72] is the sub-list for method output_type
-	52, // [52:62] is the sub-list for method input_type
-	52, // [52:52] is the sub-list for extension type_name
-	52, // [52:52] is the sub-list for extension extendee
-	0,  // [0:52] is the sub-list for field type_name
+	44, // 44: schema.SchemaLeafType.enum_values:type_name -> schema.SchemaLeafType.EnumValuesEntry
+	45, // 45: schema.PathElem.key:type_name -> schema.PathElem.KeyEntry
+	33, // 46: schema.Path.elem:type_name -> schema.PathElem
+	37, // 47: schema.SchemaMinMaxType.min:type_name -> schema.Number
+	37, // 48: schema.SchemaMinMaxType.max:type_name -> schema.Number
+	46, // 49: schema.ChoiceInfo.choice:type_name -> schema.ChoiceInfo.ChoiceEntry
+	47, // 50: schema.ChoiceInfoChoice.case:type_name -> schema.ChoiceInfoChoice.CaseEntry
+	40, // 51: schema.ChoiceInfo.ChoiceEntry.value:type_name -> schema.ChoiceInfoChoice
+	41, // 52: schema.ChoiceInfoChoice.CaseEntry.value:type_name -> schema.ChoiceCase
+	5,  // 53: schema.SchemaServer.GetSchemaDetails:input_type -> schema.GetSchemaDetailsRequest
+	7,  // 54: schema.SchemaServer.ListSchema:input_type -> schema.ListSchemaRequest
+	9,  // 55: schema.SchemaServer.GetSchema:input_type -> schema.GetSchemaRequest
+	12, // 56: schema.SchemaServer.CreateSchema:input_type -> schema.CreateSchemaRequest
+	14, // 57: schema.SchemaServer.ReloadSchema:input_type -> schema.ReloadSchemaRequest
+	16, // 58: schema.SchemaServer.DeleteSchema:input_type -> schema.DeleteSchemaRequest
+	18, // 59: schema.SchemaServer.UploadSchema:input_type -> schema.UploadSchemaRequest
+	20, // 60: schema.SchemaServer.ToPath:input_type -> schema.ToPathRequest
+	22, // 61: schema.SchemaServer.ExpandPath:input_type -> schema.ExpandPathRequest
+	9,  // 62: schema.SchemaServer.GetSchemaElements:input_type -> schema.GetSchemaRequest
+	6,  // 63: schema.SchemaServer.GetSchemaDetails:output_type -> schema.GetSchemaDetailsResponse
+	8,  // 64: schema.SchemaServer.ListSchema:output_type -> schema.ListSchemaResponse
+	10, // 65: schema.SchemaServer.GetSchema:output_type -> schema.GetSchemaResponse
+	13, // 66: schema.SchemaServer.CreateSchema:output_type -> schema.CreateSchemaResponse
+	15, // 67: schema.SchemaServer.ReloadSchema:output_type -> schema.ReloadSchemaResponse
+	17, // 68: schema.SchemaServer.DeleteSchema:output_type -> schema.DeleteSchemaResponse
+	26, // 69: schema.SchemaServer.UploadSchema:output_type -> schema.UploadSchemaResponse
+	21, // 70: schema.SchemaServer.ToPath:output_type -> schema.ToPathResponse
+	23, // 71: schema.SchemaServer.ExpandPath:output_type -> schema.ExpandPathResponse
+	10, // 72: schema.SchemaServer.GetSchemaElements:output_type -> schema.GetSchemaResponse
+	63, // [63:73] is the sub-list for method output_type
+	53, // [53:63] is the sub-list for method input_type
+	53, // [53:53] is the sub-list for extension type_name
+	53, // [53:53] is the sub-list for extension extendee
+	0,  // [0:53] is the sub-list for field type_name
 }
 
 func init() { file_schema_proto_init() }
@@ -3132,7 +3172,7 @@ func file_schema_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_schema_proto_rawDesc), len(file_schema_proto_rawDesc)),
 			NumEnums:      4,
-			NumMessages:   43,
+			NumMessages:   44,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -11,3 +11,27 @@ func (s *SchemaElem) IsState() bool {
 	}
 	return false
 }
+
+// ModuleName returns the name of the YANG module the schema element is defined in.
+func (s *SchemaElem) ModuleName() string {
+	switch x := s.GetSchema().(type) {
+	case *SchemaElem_Container:
+		return x.Container.GetModuleName()
+	case *SchemaElem_Field:
+		return x.Field.GetModuleName()
+	case *SchemaElem_Leaflist:
+		return x.Leaflist.GetModuleName()
+	}
+	return ""
+}
+
+// LeafType returns the type of a leaf or leaf-list schema element, nil for containers and lists.
+func (s *SchemaElem) LeafType() *SchemaLeafType {
+	switch x := s.GetSchema().(type) {
+	case *SchemaElem_Field:
+		return x.Field.GetType()
+	case *SchemaElem_Leaflist:
+		return x.Leaflist.GetType()
+	}
+	return nil
+}
@@ -3,8 +3,8 @@ package sdcpb
 import (
 	"bytes"
 	"cmp"
+	"encoding/base64"
 	"fmt"
-	"math"
 	"reflect"
 	"slices"
 	"strconv"
@@ -58,23 +58,7 @@ func (tv *TypedValue) Cmp(other *TypedValue) int {
 	case *TypedValue_BytesVal:
 		return bytes.Compare(tv.GetBytesVal(), other.GetBytesVal())
 	case *TypedValue_DecimalVal:
-		dtv := tv.GetDecimalVal()
-		dother := other.GetDecimalVal()
-
-		// pick the higher precision
-		maxPrec := int(dtv.Precision)
-		if int(dother.Precision) > maxPrec {
-			maxPrec = int(dother.Precision)
-		}
-
-		// rescale digits to the same base
-		scaletv := int(math.Pow10(maxPrec - int(dtv.Precision)))
-		scaleother := int(math.Pow10(maxPrec - int(dother.Precision)))
-
-		ntv := dtv.Digits * int64(scaletv)
-		nother := dother.Digits * int64(scaleother)
-
-		return cmp.Compare(ntv, nother)
+		return tv.GetDecimalVal().ToDecimal().Cmp(other.GetDecimalVal().ToDecimal())
 	case *TypedValue_DoubleVal:
 		return cmp.Compare(tv.GetDoubleVal(), other.GetDoubleVal())
 	case *TypedValue_EmptyVal:
@@ -125,29 +109,9 @@ func (tv *TypedValue) ToString() string {
 	case *TypedValue_BoolVal:
 		return strconv.FormatBool(tv.GetBoolVal())
 	case *TypedValue_BytesVal:
-		return string(tv.GetBytesVal()) // questionable...
+		return base64.StdEncoding.EncodeToString(tv.GetBytesVal())
 	case *TypedValue_DecimalVal:
-		d := tv.GetDecimalVal()
-		digitsStr := strconv.FormatInt(d.Digits, 10)
-		negative := false
-		if d.Digits < 0 {
-			negative = true
-			digitsStr = digitsStr[1:] // Remove the "-" sign for processing
-		}
-		// Add leading zeros if necessary
-		for uint32(len(digitsStr)) <= d.Precision {
-			digitsStr = "0" + digitsStr
-		}
-		// Insert the decimal point
-		if d.Precision > 0 {
-			decimalPointIndex := len(digitsStr) - int(d.Precision)
-			digitsStr = digitsStr[:decimalPointIndex] + "." + digitsStr[decimalPointIndex:]
-		}
-		// Add back the negative sign if necessary
-		if negative {
-			digitsStr = "-" + digitsStr
-		}
-		return digitsStr
+		return tv.GetDecimalVal().ToDecimal().Text()
 	case *TypedValue_DoubleVal:
 		return strconv.FormatFloat(tv.GetDoubleVal(), byte('e'), -1, 64)
 	case *TypedValue_EmptyVal:
@@ -0,0 +1,127 @@
+package sdcpb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ToCanonical returns the canonical string representation of the value as defined in RFC 7950.
+// The schema leaf type is optional, it provides the fraction-digits of decimal64 values, the declared order of bits
+// and the prefixes of identityrefs. binary values are rendered in base64 and empty values as the empty string.
+func (tv *TypedValue) ToCanonical(slt *SchemaLeafType) string {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_StringVal:
+		if bitsType := leafTypeOf(slt, "bits"); bitsType != nil {
+			return canonicalBits(v.StringVal, bitsType.GetBits())
+		}
+		return v.StringVal
+	case *TypedValue_AsciiVal:
+		return v.AsciiVal
+	case *TypedValue_BoolVal:
+		return strconv.FormatBool(v.BoolVal)
+	case *TypedValue_IntVal:
+		return strconv.FormatInt(v.IntVal, 10)
+	case *TypedValue_UintVal:
+		return strconv.FormatUint(v.UintVal, 10)
+	case *TypedValue_DecimalVal:
+		d := v.DecimalVal.ToDecimal()
+		if fd := leafTypeOf(slt, "decimal64").GetFractionDigits(); fd > 0 {
+			return d.Rescale(fd, RoundHalfEven).Text()
+		}
+		return d.String()
+	case *TypedValue_DoubleVal:
+		return strconv.FormatFloat(v.DoubleVal, 'f', -1, 64)
+	case *TypedValue_FloatVal:
+		return strconv.FormatFloat(float64(v.FloatVal), 'f', -1, 32)
+	case *TypedValue_BytesVal:
+		return base64.StdEncoding.EncodeToString(v.BytesVal)
+	case *TypedValue_ProtoBytes:
+		return base64.StdEncoding.EncodeToString(v.ProtoBytes)
+	case *TypedValue_AnyVal:
+		return base64.StdEncoding.EncodeToString(v.AnyVal.GetValue())
+	case *TypedValue_EmptyVal:
+		return ""
+	case *TypedValue_IdentityrefVal:
+		prefix := v.IdentityrefVal.GetPrefix()
+		if prefix == "" {
+			prefix = leafTypeOf(slt, "identityref").GetIdentityPrefixesMap()[v.IdentityrefVal.GetValue()]
+		}
+		if prefix == "" {
+			return v.IdentityrefVal.GetValue()
+		}
+		return prefix + ":" + v.IdentityrefVal.GetValue()
+	case *TypedValue_JsonVal:
+		return string(v.JsonVal)
+	case *TypedValue_JsonIetfVal:
+		return string(v.JsonIetfVal)
+	case *TypedValue_LeaflistVal:
+		rs := make([]string, 0, len(v.LeaflistVal.GetElement()))
+		for _, e := range v.LeaflistVal.GetElement() {
+			rs = append(rs, e.ToCanonical(slt))
+		}
+		return strings.Join(rs, ",")
+	}
+	return ""
+}
+
+// ToJSONIETFValue returns the value as the scalar (or array for leaf-lists) it is encoded as in JSON_IETF, see RFC 7951.
+// The schema leaf type determines the encoding of integers, int64 and uint64 values are always quoted. Without type
+// information, integers outside the 32-bit range are quoted. decimal64 values are quoted as well, empty is [null]
+// and binary values are base64 encoded. JSON and JSON_IETF values are returned as json.RawMessage.
+func (tv *TypedValue) ToJSONIETFValue(slt *SchemaLeafType) (any, error) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_JsonVal:
+		return json.RawMessage(v.JsonVal), nil
+	case *TypedValue_JsonIetfVal:
+		return json.RawMessage(v.JsonIetfVal), nil
+	case *TypedValue_ProtoBytes, *TypedValue_AnyVal:
+		return tv.ToCanonical(slt), nil
+	case *TypedValue_LeaflistVal:
+		result := make([]any, 0, len(v.LeaflistVal.GetElement()))
+		for _, e := range v.LeaflistVal.GetElement() {
+			ev, err := e.ToJSONIETFValue(slt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, ev)
+		}
+		return result, nil
+	}
+	return jsonValue(tv, slt, true)
+}
+
+// leafTypeOf resolves leafrefs and unions of the schema leaf type to the (first) type named typ, nil if there is none.
+func leafTypeOf(slt *SchemaLeafType, typ string) *SchemaLeafType {
+	switch slt.GetType() {
+	case typ:
+		return slt
+	case "leafref":
+		return leafTypeOf(slt.GetLeafrefTargetType(), typ)
+	case "union":
+		for _, ut := range slt.GetUnionTypes() {
+			if r := leafTypeOf(ut, typ); r != nil {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalBits orders the bit names of value in the declared order, unknown names are kept at the end.
+func canonicalBits(value string, bits []*Bit) string {
+	names := strings.Fields(value)
+	order := func(name string) int {
+		i := slices.IndexFunc(bits, func(b *Bit) bool { return b.GetName() == name })
+		if i < 0 {
+			return len(bits)
+		}
+		return i
+	}
+	slices.SortStableFunc(names, func(a, b string) int {
+		return order(a) - order(b)
+	})
+	return strings.Join(names, " ")
+}
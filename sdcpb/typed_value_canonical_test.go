@@ -0,0 +1,100 @@
+package sdcpb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestTypedValue_ToCanonical(t *testing.T) {
+	decTv := func(digits int64, precision uint32) *TypedValue {
+		return &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: digits, Precision: precision}}}
+	}
+	bitsType := &SchemaLeafType{
+		Type: "bits",
+		Bits: []*Bit{{Name: "up", Position: 0}, {Name: "running", Position: 1}, {Name: "lower-layer-down", Position: 2}},
+	}
+	identityType := &SchemaLeafType{
+		Type:                "identityref",
+		IdentityPrefixesMap: map[string]string{"ethernetCsmacd": "ianaift"},
+	}
+
+	tests := []struct {
+		name string
+		tv   *TypedValue
+		slt  *SchemaLeafType
+		want string
+	}{
+		{"double", &TypedValue{Value: &TypedValue_DoubleVal{DoubleVal: 1500.25}}, nil, "1500.25"},
+		{"float", &TypedValue{Value: &TypedValue_FloatVal{FloatVal: 0.1}}, nil, "0.1"},
+		{"bytes", &TypedValue{Value: &TypedValue_BytesVal{BytesVal: []byte("hello")}}, nil, "aGVsbG8="},
+		{"proto bytes", &TypedValue{Value: &TypedValue_ProtoBytes{ProtoBytes: []byte{0xff, 0x00}}}, nil, "/wA="},
+		{"any", &TypedValue{Value: &TypedValue_AnyVal{AnyVal: &anypb.Any{Value: []byte{0x01}}}}, nil, "AQ=="},
+		{"empty", &TypedValue{Value: &TypedValue_EmptyVal{EmptyVal: &emptypb.Empty{}}}, nil, ""},
+		{"decimal padded", decTv(15, 1), &SchemaLeafType{Type: "decimal64", FractionDigits: 3}, "1.500"},
+		{"decimal via union", decTv(-5, 0), &SchemaLeafType{Type: "union", UnionTypes: []*SchemaLeafType{{Type: "string"}, {Type: "decimal64", FractionDigits: 2}}}, "-5.00"},
+		{"decimal without type", decTv(1500, 3), nil, "1.5"},
+		{"bits in declared order", &TypedValue{Value: &TypedValue_StringVal{StringVal: "lower-layer-down  up"}}, bitsType, "up lower-layer-down"},
+		{"bits via leafref", &TypedValue{Value: &TypedValue_StringVal{StringVal: "running up"}}, &SchemaLeafType{Type: "leafref", LeafrefTargetType: bitsType}, "up running"},
+		{"identityref prefix", &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Value: "ethernetCsmacd", Prefix: "ianaift", Module: "iana-if-type"}}}, nil, "ianaift:ethernetCsmacd"},
+		{"identityref prefix from type", &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Value: "ethernetCsmacd"}}}, identityType, "ianaift:ethernetCsmacd"},
+		{"int", &TypedValue{Value: &TypedValue_IntVal{IntVal: -42}}, nil, "-42"},
+		{"nil", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tv.ToCanonical(tt.slt); got != tt.want {
+				t.Errorf("ToCanonical() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypedValue_ToJSONIETFValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		tv      *TypedValue
+		slt     *SchemaLeafType
+		want    string
+		wantErr bool
+	}{
+		{"int32 leaf", &TypedValue{Value: &TypedValue_IntVal{IntVal: -5}}, &SchemaLeafType{Type: "int32"}, `-5`, false},
+		{"small int64 leaf", &TypedValue{Value: &TypedValue_IntVal{IntVal: 5}}, &SchemaLeafType{Type: "int64"}, `"5"`, false},
+		{"small uint64 via leafref", &TypedValue{Value: &TypedValue_UintVal{UintVal: 5}}, &SchemaLeafType{Type: "leafref", LeafrefTargetType: &SchemaLeafType{Type: "uint64"}}, `"5"`, false},
+		{"large int without type", &TypedValue{Value: &TypedValue_IntVal{IntVal: 1 << 40}}, nil, `"1099511627776"`, false},
+		{"small int without type", &TypedValue{Value: &TypedValue_IntVal{IntVal: 5}}, nil, `5`, false},
+		{"decimal", &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 125, Precision: 2}}}, nil, `"1.25"`, false},
+		{"empty", &TypedValue{Value: &TypedValue_EmptyVal{EmptyVal: &emptypb.Empty{}}}, nil, `[null]`, false},
+		{"bytes", &TypedValue{Value: &TypedValue_BytesVal{BytesVal: []byte("hello")}}, nil, `"aGVsbG8="`, false},
+		{"identityref", &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Value: "ethernetCsmacd", Prefix: "ianaift", Module: "iana-if-type"}}}, nil, `"iana-if-type:ethernetCsmacd"`, false},
+		{"json ietf", &TypedValue{Value: &TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{"a":1}`)}}, nil, `{"a":1}`, false},
+		{"leaflist", &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+			{Value: &TypedValue_UintVal{UintVal: 1}},
+			{Value: &TypedValue_UintVal{UintVal: 1 << 33}},
+		}}}}, &SchemaLeafType{Type: "uint64"}, `["1","8589934592"]`, false},
+		{"unset", &TypedValue{}, nil, ``, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.tv.ToJSONIETFValue(tt.slt)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("wanted error, got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("ToJSONIETFValue() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
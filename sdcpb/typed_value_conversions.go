@@ -1,10 +1,13 @@
 package sdcpb
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"math"
-	"regexp"
+	"math/big"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -29,6 +32,9 @@ func SchemaElemToTV(schemaObject *SchemaElem, v string, ts uint64) (*TypedValue,
 	return TVFromString(schemaType, v, ts)
 }
 
+// TVFromString converts the string representation of a value into a TypedValue of the given schema type.
+// Integers are parsed in the lexical forms of RFC 7950 section 9.2.1, a "0x" prefix denotes hexadecimal and
+// a leading 0 octal notation. A value like "010", e.g. from XMLToUpdates, therefore becomes 8 rather than 10.
 func TVFromString(schemaType *SchemaLeafType, v string, ts uint64) (*TypedValue, error) {
 	if schemaType == nil {
 		return nil, fmt.Errorf("schemaType cannot be nil")
@@ -44,8 +50,6 @@ func TVFromString(schemaType *SchemaLeafType, v string, ts uint64) (*TypedValue,
 	case "boolean":
 		tv, err = ConvertBoolean(v, schemaType)
 	case "int8":
-		// TODO: HEX and OCTAL pre-processing for all INT types
-		// https://www.rfc-editor.org/rfc/rfc6020.html#page-112
 		tv, err = ConvertInt8(v, schemaType)
 	case "int16":
 		tv, err = ConvertInt16(v, schemaType)
@@ -73,7 +77,7 @@ func TVFromString(schemaType *SchemaLeafType, v string, ts uint64) (*TypedValue,
 		tv, err = ConvertLeafRef(v, schemaType)
 	case "identityref": //TODO: https://www.rfc-editor.org/rfc/rfc6020.html#section-9.10
 		tv, err = ConvertIdentityRef(v, schemaType)
-	case "instance-identifier": //TODO: https://www.rfc-editor.org/rfc/rfc6020.html#section-9.13
+	case "instance-identifier": // https://www.rfc-editor.org/rfc/rfc7950.html#section-9.13
 		tv, err = ConvertInstanceIdentifier(v, schemaType)
 	case "decimal64":
 		// TODO: is the following TODO still valid? I think not
@@ -91,9 +95,209 @@ func TVFromString(schemaType *SchemaLeafType, v string, ts uint64) (*TypedValue,
 	return tv, nil
 }
 
+// ConvertInstanceIdentifier parses the value into an absolute path and validates the element names, the predicates
+// and the module prefixes. All node names must be prefixed, see ParseInstanceIdentifier. A prefix is valid if it is
+// a key or a value of the module_prefix_map, if that is set.
+// The returned value carries the canonical form of the path, see FormatInstanceIdentifier.
+// Whether the referenced elements and instances exist is checked via ValidateInstanceIdentifier.
 func ConvertInstanceIdentifier(value string, slt *SchemaLeafType) (*TypedValue, error) {
-	// delegate to string, validation is left for a different party at a later stage in processing
-	return ConvertString(value, slt)
+	p, err := ParseInstanceIdentifier(value, slt)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := FormatInstanceIdentifier(p)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedValue{
+		Value: &TypedValue_StringVal{
+			StringVal: canonical,
+		},
+	}, nil
+}
+
+// FormatInstanceIdentifier renders the path as instance-identifier as per RFC 7950 section 9.13.
+// Every predicate value is an XPath 1.0 literal, quoted by single quotes or, if the value contains a single quote,
+// by double quotes. Literals have no escape character, so values containing both quote characters are rejected.
+func FormatInstanceIdentifier(p *Path) (string, error) {
+	sb := &strings.Builder{}
+	for _, pe := range p.GetElem() {
+		sb.WriteByte('/')
+		sb.WriteString(pe.GetName())
+		for _, k := range slices.Sorted(maps.Keys(pe.GetKey())) {
+			v := pe.GetKey()[k]
+			quote := "'"
+			if strings.Contains(v, "'") {
+				if strings.Contains(v, `"`) {
+					return "", fmt.Errorf("value %q of key %s contains both quote characters", v, k)
+				}
+				quote = `"`
+			}
+			sb.WriteString("[" + k + "=" + quote + v + quote + "]")
+		}
+	}
+	return sb.String(), nil
+}
+
+// ParseInstanceIdentifier parses and validates the instance-identifier value, see ConvertInstanceIdentifier.
+// All node names, including the key names of the predicates, must be qualified with a module prefix.
+// https://datatracker.ietf.org/doc/html/rfc7950#section-9.13
+// Positional predicates like [1], which select an entry of a keyless list or a state leaf-list, can not be represented
+// by a Path and are rejected.
+func ParseInstanceIdentifier(value string, slt *SchemaLeafType) (*Path, error) {
+	return parseInstanceIdentifier(value, slt, false)
+}
+
+// ParseInstanceIdentifierLenient is like ParseInstanceIdentifier, but also accepts unprefixed node names, as used
+// by the paths of this module and by the JSON encoding for nodes of the same module as their parent.
+// https://datatracker.ietf.org/doc/html/rfc7951#section-6.11
+func ParseInstanceIdentifierLenient(value string, slt *SchemaLeafType) (*Path, error) {
+	return parseInstanceIdentifier(value, slt, true)
+}
+
+func parseInstanceIdentifier(value string, slt *SchemaLeafType, lenient bool) (*Path, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "/") {
+		return nil, fmt.Errorf("instance-identifier %q is not an absolute path", value)
+	}
+	escaped, err := escapeInstanceIdentifierLiterals(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance-identifier %q: %w", value, err)
+	}
+	p, err := ParsePath(escaped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance-identifier %q: %w", value, err)
+	}
+	if len(p.GetElem()) == 0 {
+		return nil, fmt.Errorf("instance-identifier %q does not reference a node", value)
+	}
+	for _, pe := range p.GetElem() {
+		if err := validateInstanceIdentifierName(pe.GetName(), slt, lenient); err != nil {
+			return nil, fmt.Errorf("invalid instance-identifier %q: %w", value, err)
+		}
+		for k := range pe.GetKey() {
+			// leaf-list entries are referenced via [.=value]
+			if k == "." {
+				continue
+			}
+			if err := validateInstanceIdentifierName(k, slt, lenient); err != nil {
+				return nil, fmt.Errorf("invalid instance-identifier %q: %w", value, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// escapeInstanceIdentifierLiterals prepares the XPath 1.0 syntax for ParsePath. Backslashes within literals are
+// escaped, since they have no special meaning in XPath, and positional predicates are rejected.
+func escapeInstanceIdentifierLiterals(value string) (string, error) {
+	sb := &strings.Builder{}
+	var quote byte
+	predicateStart := -1
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' {
+				sb.WriteByte('\\')
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			predicateStart = i + 1
+		case c == ']' && predicateStart >= 0:
+			predicate := strings.TrimSpace(value[predicateStart:i])
+			if predicate != "" && strings.Trim(predicate, "0123456789") == "" {
+				return "", fmt.Errorf("positional predicate [%s] is not supported", predicate)
+			}
+			predicateStart = -1
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+// validateInstanceIdentifierName checks that the name is an identifier with a known prefix. Unless lenient is set,
+// the prefix is required.
+func validateInstanceIdentifierName(name string, slt *SchemaLeafType, lenient bool) error {
+	prefix, local, prefixed := strings.Cut(name, ":")
+	if !prefixed {
+		local, prefix = prefix, ""
+	}
+	if !isYangIdentifier(local) || prefixed && !isYangIdentifier(prefix) {
+		return fmt.Errorf("%q is not a valid node identifier", name)
+	}
+	if !prefixed && !lenient {
+		return fmt.Errorf("%q is not qualified with a module prefix", name)
+	}
+	if prefix == "" || len(slt.GetModulePrefixMap()) == 0 {
+		return nil
+	}
+	for module, p := range slt.GetModulePrefixMap() {
+		if prefix == module || prefix == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown module prefix %q", prefix)
+}
+
+// isYangIdentifier checks the identifier syntax of RFC 7950 section 14.
+func isYangIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateInstanceIdentifier checks that every element of the instance-identifier path exists in the schema and
+// only uses declared keys. Unless the type has optional_instance (require-instance false) set, the referenced
+// instance has to exist as well, which is checked via instanceExists. The instanceExists may be nil to skip this check.
+func ValidateInstanceIdentifier(p *Path, slt *SchemaLeafType, lookup SchemaLookupFunc, instanceExists func(*Path) (bool, error)) error {
+	current := &Path{IsRootBased: true}
+	for _, pe := range p.GetElem() {
+		current = current.CopyPathAddElem(pe)
+		schema, err := lookup(current)
+		if err != nil {
+			return fmt.Errorf("instance-identifier %s: %w", p.ToXPath(false), err)
+		}
+		if schema == nil || schema.GetSchema() == nil {
+			return fmt.Errorf("instance-identifier %s: element %s does not exist", p.ToXPath(false), current.ToXPath(false))
+		}
+		for k := range pe.GetKey() {
+			if k == "." && schema.GetLeaflist() != nil {
+				continue
+			}
+			_, name := splitModuleName(k)
+			declared := false
+			for _, ks := range schema.GetContainer().GetKeys() {
+				declared = declared || ks.GetName() == name
+			}
+			if !declared {
+				return fmt.Errorf("instance-identifier %s: %s is not a key of %s", p.ToXPath(false), k, pe.GetName())
+			}
+		}
+	}
+	if slt.GetOptionalInstance() || instanceExists == nil {
+		return nil
+	}
+	exists, err := instanceExists(p)
+	if err != nil {
+		return fmt.Errorf("instance-identifier %s: %w", p.ToXPath(false), err)
+	}
+	if !exists {
+		return fmt.Errorf("instance-identifier %s: referenced instance does not exist", p.ToXPath(false))
+	}
+	return nil
 }
 
 func ConvertIdentityRef(value string, schemaType *SchemaLeafType) (*TypedValue, error) {
@@ -122,10 +326,24 @@ func ConvertIdentityRef(value string, schemaType *SchemaLeafType) (*TypedValue,
 	}, nil
 }
 
+// ConvertBinary decodes the base64 encoded value, the length restriction applies to the number of decoded octets.
+// https://www.rfc-editor.org/rfc/rfc7950.html#section-9.8
 func ConvertBinary(value string, slt *SchemaLeafType) (*TypedValue, error) {
-	// Binary is basically a base64 encoded string that might carry a length restriction
-	// so we should be fine with delegating to string
-	return ConvertString(value, slt)
+	// line breaks and indentation are common in XML encoded values and not part of the base64 alphabet
+	b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 binary value: %w", err)
+	}
+	if len(slt.GetLength()) != 0 {
+		if _, err := convertUint(strconv.Itoa(len(b)), slt.GetLength(), nil); err != nil {
+			return nil, fmt.Errorf("binary length in octets: %w", err)
+		}
+	}
+	return &TypedValue{
+		Value: &TypedValue_BytesVal{
+			BytesVal: b,
+		},
+	}, nil
 }
 
 func ConvertLeafRef(value string, slt *SchemaLeafType) (*TypedValue, error) {
@@ -192,6 +410,46 @@ func ConvertSdcpbNumberToInt64(mm *Number) (int64, error) {
 	return int64(mm.Value), nil
 }
 
+// parseYangInteger parses the YANG integer lexical forms of RFC 7950 section 9.2.1, which are decimal, hexadecimal
+// with a lowercase 0x prefix and octal with a leading 0, each optionally preceded by a sign.
+// The sign and the magnitude are returned separately so that the callers can apply their own bounds.
+func parseYangInteger(value string) (bool, uint64, error) {
+	digits := value
+	negative := strings.HasPrefix(digits, "-")
+	if negative || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	base := 10
+	switch {
+	case strings.HasPrefix(digits, "0x"):
+		base = 16
+		digits = digits[2:]
+	case len(digits) > 1 && digits[0] == '0':
+		base = 8
+		digits = digits[1:]
+	}
+	// strconv would accept a sign or underscores in the digits, neither is valid here
+	if digits == "" || strings.ContainsAny(digits, "+-_") {
+		return false, 0, fmt.Errorf("invalid integer value %q", value)
+	}
+	magnitude, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid integer value %q: %w", value, err)
+	}
+	return negative, magnitude, nil
+}
+
+// ConvertSdcpbNumberToRat returns the exact value of the number, taking its precision into account.
+func ConvertSdcpbNumberToRat(mm *Number) *big.Rat {
+	d := Decimal{unscaled: new(big.Int).SetUint64(mm.GetValue()), scale: mm.GetPrecision()}
+	if mm.GetNegative() {
+		d = d.Neg()
+	}
+	return d.Rat()
+}
+
+// convertUint parses value in decimal, hexadecimal or octal notation and validates it against the ranges.
+// https://www.rfc-editor.org/rfc/rfc7950.html#section-9.2.1
 func convertUint(value string, minMaxs []*SchemaMinMaxType, ranges *utils.Rnges[uint64]) (*TypedValue, error) {
 	if ranges == nil {
 		ranges = utils.NewRnges[uint64]()
@@ -208,10 +466,13 @@ func convertUint(value string, minMaxs []*SchemaMinMaxType, ranges *utils.Rnges[
 		ranges.AddRange(min, max)
 	}
 
-	uValue, err := strconv.ParseUint(value, 10, 64)
+	negative, uValue, err := parseYangInteger(value)
 	if err != nil {
 		return nil, err
 	}
+	if negative && uValue != 0 {
+		return nil, fmt.Errorf("%q is negative, expected an unsigned integer", value)
+	}
 	// validate the value against the ranges
 	valid := ranges.IsWithinAnyRange(uValue)
 	if !valid {
@@ -252,6 +513,8 @@ func ConvertUint64(value string, lst *SchemaLeafType) (*TypedValue, error) {
 	return convertUint(value, lst.Range, ranges)
 }
 
+// convertInt parses value in decimal, hexadecimal or octal notation and validates it against the ranges.
+// https://www.rfc-editor.org/rfc/rfc7950.html#section-9.2.1
 func convertInt(value string, minMaxs []*SchemaMinMaxType, ranges *utils.Rnges[int64]) (*TypedValue, error) {
 	for _, x := range minMaxs {
 		min, err := ConvertSdcpbNumberToInt64(x.Min)
@@ -265,8 +528,11 @@ func convertInt(value string, minMaxs []*SchemaMinMaxType, ranges *utils.Rnges[i
 		ranges.AddRange(min, max)
 	}
 
-	// validate the value against the ranges
-	iValue, err := strconv.ParseInt(value, 10, 64)
+	negative, magnitude, err := parseYangInteger(value)
+	if err != nil {
+		return nil, err
+	}
+	iValue, err := ConvertSdcpbNumberToInt64(&Number{Value: magnitude, Negative: negative})
 	if err != nil {
 		return nil, err
 	}
@@ -309,37 +575,6 @@ func ConvertInt64(value string, lst *SchemaLeafType) (*TypedValue, error) {
 	return convertInt(value, lst.Range, ranges)
 }
 
-func xmlRegexConvert(s string) string {
-
-	cTest := func(r rune, prev rune) bool {
-		// if ^ is not following a [ or if $ we want to return true
-		return (r == '^' && prev != '[') || r == '$'
-	}
-
-	b := strings.Builder{}
-	b.Grow(len(s) + len(s)/4)
-	slashes := 0
-	prevR := rune(0)
-
-	for _, r := range s {
-		if r == '\\' {
-			slashes++
-			prevR = r
-			b.WriteRune(r)
-			continue
-		}
-
-		if cTest(r, prevR) && slashes%2 == 0 {
-			b.WriteRune('\\')
-		}
-
-		slashes = 0
-		prevR = r
-		b.WriteRune(r)
-	}
-	return b.String()
-}
-
 func ConvertString(value string, lst *SchemaLeafType) (*TypedValue, error) {
 	// check length of the string if the length property is set
 	// length will contain a range like string definition "5..60" or "7..10|40..45"
@@ -356,16 +591,9 @@ func ConvertString(value string, lst *SchemaLeafType) (*TypedValue, error) {
 	// If the type has multiple "pattern" statements, the expressions are
 	// ANDed together, i.e., all such expressions have to match.
 	for _, sp := range lst.Patterns {
-		// The set of metacharacters is not the same between XML schema and perl/python/go REs
-		// the set of metacharacters for XML is: .\?*+{}()[] (https://www.w3.org/TR/xmlschema-2/#dt-metac)
-		// the set of metacharacters defined in go is: \.+*?()|[]{}^$ (go/libexec/src/regexp/regexp.go:714)
-		// we need therefore to escape some values
-		// TODO check about '^'
-
-		escaped := xmlRegexConvert(sp.Pattern)
-		re, err := regexp.Compile(escaped)
+		// YANG patterns are XML Schema regular expressions, see compileXSDPattern
+		re, err := compileXSDPattern(sp.Pattern)
 		if err != nil {
-			//TODO: Do we want to stop here?
 			logf.DefaultLogger.Error(err, "unable to compile regex", "pattern", sp.Pattern)
 			return nil, fmt.Errorf("unable to compile regex: %w", err)
 		}
@@ -392,57 +620,16 @@ func ConvertString(value string, lst *SchemaLeafType) (*TypedValue, error) {
 }
 
 func ConvertDecimal64(value string, lst *SchemaLeafType) (*TypedValue, error) {
-	v := strings.TrimSpace(value)
-	if v == "" {
-		return nil, fmt.Errorf("empty decimal64 string")
-	}
-
-	neg := false
-	if strings.HasPrefix(v, "-") {
-		neg = true
-		v = v[1:]
-	} else if strings.HasPrefix(v, "+") {
-		v = v[1:]
-	}
-
-	if v == "" {
-		return nil, fmt.Errorf("no digits after sign")
-	}
-
-	parts := strings.SplitN(v, ".", 2)
-	intPart := parts[0]
-	fracPart := ""
-	if len(parts) == 2 {
-		fracPart = parts[1]
-	}
-
-	// Require at least one digit total (either int or frac)
-	if intPart == "" && fracPart == "" {
-		return nil, fmt.Errorf("no digits in decimal64 value")
-	}
-
-	if intPart == "" {
-		intPart = "0"
-	}
-
-	combined := intPart + fracPart
-	if combined == "" {
-		return nil, fmt.Errorf("no digits to parse")
-	}
-
-	digits, err := strconv.ParseInt(combined, 10, 64)
+	d, err := ParseDecimal(value)
 	if err != nil {
 		return nil, err
 	}
-	if neg {
-		digits = -digits
+	if err := validateDecimal64(value, d, lst); err != nil {
+		return nil, err
 	}
-
-	precision := uint32(len(fracPart))
-
-	d64 := &Decimal64{
-		Digits:    digits,
-		Precision: precision,
+	d64, err := d.ToDecimal64()
+	if err != nil {
+		return nil, err
 	}
 
 	return &TypedValue{
@@ -452,6 +639,30 @@ func ConvertDecimal64(value string, lst *SchemaLeafType) (*TypedValue, error) {
 	}, nil
 }
 
+// validateDecimal64 checks the value against the fraction-digits and the range restrictions of the type.
+func validateDecimal64(value string, d Decimal, lst *SchemaLeafType) error {
+	if fd := lst.GetFractionDigits(); fd > 0 {
+		// trailing zeros do not count towards the fraction digits
+		scaled := d.Rescale(fd, RoundTowardZero)
+		if scaled.Cmp(d) != 0 {
+			return fmt.Errorf("%q has more than %d fraction digits", value, fd)
+		}
+		// the value must be representable as 64 bit digits with fraction-digits precision
+		if !scaled.int().IsInt64() {
+			return fmt.Errorf("%q exceeds the decimal64 range for %d fraction digits", value, fd)
+		}
+	}
+
+	ranges := utils.NewDecimalRnges()
+	for _, x := range lst.GetRange() {
+		ranges.AddRange(ConvertSdcpbNumberToRat(x.GetMin()), ConvertSdcpbNumberToRat(x.GetMax()))
+	}
+	if !ranges.IsWithinAnyRange(d.Rat()) {
+		return fmt.Errorf("%q not within ranges: %s", value, ranges.String())
+	}
+	return nil
+}
+
 func ConvertUnion(value string, slts []*SchemaLeafType) (*TypedValue, error) {
 	// iterate over the union types try to convert without error
 	for _, slt := range slts {
@@ -525,6 +736,18 @@ func ConvertJsonValueToTv(d any, slt *SchemaLeafType) (*TypedValue, error) {
 		}, nil
 	case "leafref":
 		return ConvertJsonValueToTv(d, slt.LeafrefTargetType)
+	case "binary":
+		v, ok := d.(string)
+		if !ok {
+			return nil, fmt.Errorf("error converting %v to binary", d)
+		}
+		return ConvertBinary(v, slt)
+	case "instance-identifier":
+		v, ok := d.(string)
+		if !ok {
+			return nil, fmt.Errorf("error converting %v to instance-identifier", d)
+		}
+		return ConvertInstanceIdentifier(v, slt)
 	case "identityref":
 		v, ok := d.(string)
 		if !ok {
@@ -601,19 +824,15 @@ func ConvertJsonValueToTv(d any, slt *SchemaLeafType) (*TypedValue, error) {
 			Value: &TypedValue_BoolVal{BoolVal: b},
 		}, nil
 	case "decimal64":
-		arr := strings.SplitN(d.(string), ".", 2)
-		digits, err := strconv.ParseInt(arr[0], 10, 64)
-		if err != nil {
-			return nil, err
-		}
-		precision64, err := strconv.ParseUint(arr[1], 10, 32)
-		if err != nil {
-			return nil, err
+		switch v := d.(type) {
+		case string: // decimal64 is transported as string in json_ietf
+			return ConvertDecimal64(v, slt)
+		case json.Number:
+			return ConvertDecimal64(v.String(), slt)
+		case float64:
+			return ConvertDecimal64(strconv.FormatFloat(v, 'f', -1, 64), slt)
 		}
-		precision := uint32(precision64)
-		return &TypedValue{
-			Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: digits, Precision: precision}},
-		}, nil
+		return nil, fmt.Errorf("error converting %v to decimal64", d)
 	case "union":
 		for _, ut := range slt.GetUnionTypes() {
 			tv, err := ConvertJsonValueToTv(d, ut)
@@ -1,69 +1,13 @@
 package sdcpb
 
 import (
-	"reflect"
+	"encoding/json"
+	"math"
 	"testing"
 
 	"google.golang.org/protobuf/proto"
 )
 
-func TestXMLRegexConvert(t *testing.T) {
-
-	tests := []struct {
-		name string
-		in   string
-		want string
-	}{
-		{
-			name: "anchors become literals",
-			in:   `^\d+$`,
-			want: `\^\d+\$`,
-		},
-		{
-			name: "already-escaped anchors stay escaped",
-			in:   `foo\$bar`,
-			want: `foo\$bar`,
-		},
-		{
-			name: "caret in char class is left alone, dollar is escaped",
-			in:   `[^\w]+$`,
-			want: `[^\w]+\$`,
-		},
-		{
-			name: "caret later inside char class is escaped",
-			in:   `[a^b]`,
-			want: `[a\^b]`,
-		},
-		{
-			name: "caret escaped inside char class is escaped",
-			in:   `[\^]`,
-			want: `[\^]`,
-		},
-		{
-			name: "caret in char class multiple times, dollar is escaped",
-			in:   `[^a^b]`,
-			want: `[^a\^b]`,
-		},
-		{
-			name: "anchors preceded by a single back-slash stay escaped",
-			in:   `\^test\$`,
-			want: `\^test\$`,
-		},
-		{
-			name: "empty string",
-			in:   ``,
-			want: ``,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := xmlRegexConvert(tt.in); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("XMLRegexConvert() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestValidateBitString(t *testing.T) {
 	ref := []*Bit{
 		{Name: "a", Position: 0},
@@ -165,3 +109,314 @@ func TestConvertBits(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertInstanceIdentifier(t *testing.T) {
+	slt := &SchemaLeafType{
+		Type:            "instance-identifier",
+		ModulePrefixMap: map[string]string{"ietf-interfaces": "if"},
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"known prefix", "/if:interfaces/if:interface[if:name='eth0']", "/if:interfaces/if:interface[if:name='eth0']", false},
+		{"module name as prefix", "/ietf-interfaces:interfaces", "/ietf-interfaces:interfaces", false},
+		{"leaf-list entry", "/if:system/if:dns/if:server[.='10.0.0.1']", "/if:system/if:dns/if:server[.='10.0.0.1']", false},
+		{"multiple keys sorted", `/if:routing/if:route[if:prefix="10.0.0.0/8"][if:vrf='red']`, "/if:routing/if:route[if:prefix='10.0.0.0/8'][if:vrf='red']", false},
+		{"value with single quote", `/if:users/if:user[if:name="o'neil"]`, `/if:users/if:user[if:name="o'neil"]`, false},
+		{"backslash is literal", `/if:files/if:file[if:path='c:\tmp']`, `/if:files/if:file[if:path='c:\tmp']`, false},
+		{"value with brackets", `/if:acl/if:entry[if:name='a[1]']`, `/if:acl/if:entry[if:name='a[1]']`, false},
+		{"unprefixed node", "/if:interfaces/interface[if:name='eth0']", "", true},
+		{"unprefixed key", "/if:interfaces/if:interface[name='eth0']", "", true},
+		{"positional predicate", "/if:system/if:servers/if:server[1]", "", true},
+		{"unknown prefix", "/ex:interfaces", "", true},
+		{"relative path", "if:interfaces/if:interface", "", true},
+		{"root only", "/", "", true},
+		{"invalid identifier", "/if:interfaces/if:1interface", "", true},
+		{"unterminated predicate", "/if:interfaces/if:interface[if:name=eth0", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ConvertInstanceIdentifier(tc.value, slt)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+			if got.GetStringVal() != tc.want {
+				t.Fatalf("ConvertInstanceIdentifier(%q) = %q, want %q", tc.value, got.GetStringVal(), tc.want)
+			}
+		})
+	}
+}
+
+func TestParseInstanceIdentifierLenient(t *testing.T) {
+	slt := &SchemaLeafType{ModulePrefixMap: map[string]string{"ietf-interfaces": "if"}}
+	p, err := ParseInstanceIdentifierLenient("/if:interfaces/interface[name='eth0']", slt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Path{IsRootBased: true, Elem: []*PathElem{{Name: "if:interfaces"}, {Name: "interface", Key: map[string]string{"name": "eth0"}}}}
+	if !proto.Equal(p, want) {
+		t.Errorf("ParseInstanceIdentifierLenient() = %v, want %v", p, want)
+	}
+	// prefixes are still validated
+	if _, err := ParseInstanceIdentifierLenient("/ex:interfaces", slt); err == nil {
+		t.Errorf("ParseInstanceIdentifierLenient() wanted error for an unknown prefix")
+	}
+}
+
+func TestFormatInstanceIdentifier(t *testing.T) {
+	p := &Path{Elem: []*PathElem{{Name: "users"}, {Name: "user", Key: map[string]string{"name": `it's "quoted"`}}}}
+	if got, err := FormatInstanceIdentifier(p); err == nil {
+		t.Errorf("FormatInstanceIdentifier() = %q, wanted error for a value with both quote characters", got)
+	}
+}
+
+func TestValidateInstanceIdentifier(t *testing.T) {
+	lookup := testSchemaLookup(map[string]*SchemaElem{
+		"/interface": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "interface",
+			Keys: []*LeafSchema{{Name: "name", Type: &SchemaLeafType{Type: "string"}}},
+		}}},
+		"/interface/mtu": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "mtu", Type: &SchemaLeafType{Type: "uint16"}}}},
+		"/dns-server":    {Schema: &SchemaElem_Leaflist{Leaflist: &LeafListSchema{Name: "dns-server", Type: &SchemaLeafType{Type: "string"}}}},
+	})
+	existing := func(p *Path) (bool, error) {
+		return p.ToXPath(false) == "/interface[name=eth0]/mtu", nil
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		slt     *SchemaLeafType
+		wantErr bool
+	}{
+		{"existing instance", "/interface[name=eth0]/mtu", &SchemaLeafType{}, false},
+		{"missing instance", "/interface[name=eth1]/mtu", &SchemaLeafType{}, true},
+		{"missing instance not required", "/interface[name=eth1]/mtu", &SchemaLeafType{OptionalInstance: true}, false},
+		{"unknown element", "/interface[name=eth0]/speed", &SchemaLeafType{OptionalInstance: true}, true},
+		{"undeclared key", "/interface[id=0]/mtu", &SchemaLeafType{OptionalInstance: true}, true},
+		{"leaf-list entry", "/dns-server[.=10.0.0.1]", &SchemaLeafType{OptionalInstance: true}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseInstanceIdentifierLenient(tc.value, tc.slt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ValidateInstanceIdentifier(p, tc.slt, lookup, existing)
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertIntegerLiterals(t *testing.T) {
+	iTv := func(i int64) *TypedValue {
+		return &TypedValue{Value: &TypedValue_IntVal{IntVal: i}}
+	}
+	uTv := func(u uint64) *TypedValue {
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: u}}
+	}
+	ranged := func(typ string, min, max uint64) *SchemaLeafType {
+		return &SchemaLeafType{
+			Type:  typ,
+			Range: []*SchemaMinMaxType{{Min: &Number{Value: min}, Max: &Number{Value: max}}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		slt     *SchemaLeafType
+		want    *TypedValue
+		wantErr bool
+	}{
+		{"decimal int8", "-12", &SchemaLeafType{Type: "int8"}, iTv(-12), false},
+		{"hex int8", "0x1F", &SchemaLeafType{Type: "int8"}, iTv(31), false},
+		{"negative hex int16", "-0x10", &SchemaLeafType{Type: "int16"}, iTv(-16), false},
+		{"octal int32", "017", &SchemaLeafType{Type: "int32"}, iTv(15), false},
+		{"zero", "0", &SchemaLeafType{Type: "int32"}, iTv(0), false},
+		{"int64 min in hex", "-0x8000000000000000", &SchemaLeafType{Type: "int64"}, iTv(math.MinInt64), false},
+		{"int64 overflow in hex", "0x8000000000000000", &SchemaLeafType{Type: "int64"}, nil, true},
+		{"int8 overflow in hex", "0x80", &SchemaLeafType{Type: "int8"}, nil, true},
+		{"hex uint8", "0xff", &SchemaLeafType{Type: "uint8"}, uTv(255), false},
+		{"uint8 overflow in octal", "0400", &SchemaLeafType{Type: "uint8"}, nil, true},
+		{"uint64 max in hex", "0xffffffffffffffff", &SchemaLeafType{Type: "uint64"}, uTv(math.MaxUint64), false},
+		{"negative uint", "-0x1", &SchemaLeafType{Type: "uint32"}, nil, true},
+		{"invalid octal digit", "08", &SchemaLeafType{Type: "uint16"}, nil, true},
+		{"missing hex digits", "0x", &SchemaLeafType{Type: "uint16"}, nil, true},
+		{"uppercase hex prefix", "0X1F", &SchemaLeafType{Type: "uint16"}, nil, true},
+		{"leading zero is octal", "010", &SchemaLeafType{Type: "uint16"}, uTv(8), false},
+		{"double sign", "--1", &SchemaLeafType{Type: "int16"}, nil, true},
+		{"underscores", "1_000", &SchemaLeafType{Type: "uint16"}, nil, true},
+		{"hex within range", "0x40", ranged("uint16", 0x20, 0x80), uTv(64), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := TVFromString(tc.slt, tc.value, 0)
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted error, got %v", got)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("TVFromString(%q) = %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvertDecimal64Restrictions(t *testing.T) {
+	dTv := func(digits int64, precision uint32) *TypedValue {
+		return &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: digits, Precision: precision}}}
+	}
+	slt := &SchemaLeafType{
+		Type:           "decimal64",
+		FractionDigits: 2,
+		Range: []*SchemaMinMaxType{
+			{Min: &Number{Value: 5, Negative: true, Precision: 1}, Max: &Number{Value: 1025, Precision: 2}},
+			{Min: &Number{Value: 100}, Max: &Number{Value: 200}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		value   any
+		slt     *SchemaLeafType
+		want    *TypedValue
+		wantErr bool
+	}{
+		{"within first range", "1.5", slt, dTv(15, 1), false},
+		{"lower boundary", "-0.50", slt, dTv(-50, 2), false},
+		{"upper boundary", "10.25", slt, dTv(1025, 2), false},
+		{"within second range", "150", slt, dTv(150, 0), false},
+		{"between ranges", "10.26", slt, nil, true},
+		{"below ranges", "-0.51", slt, nil, true},
+		{"too many fraction digits", "1.125", slt, nil, true},
+		{"trailing zeros beyond fraction digits", "1.500", slt, dTv(1500, 3), false},
+		{"json number", json.Number("2.25"), slt, dTv(225, 2), false},
+		{"json float", 0.125, slt, nil, true},
+		{"exceeds decimal64 for fraction digits", "92233720368547758.07", &SchemaLeafType{Type: "decimal64", FractionDigits: 3}, nil, true},
+		{"no restrictions", "123.456", &SchemaLeafType{Type: "decimal64"}, dTv(123456, 3), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *TypedValue
+			var err error
+			if s, ok := tc.value.(string); ok {
+				got, err = TVFromString(tc.slt, s, 0)
+			} else {
+				got, err = ConvertJsonValueToTv(tc.value, tc.slt)
+			}
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted error, got %v", got)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("conversion of %v = %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvertBinary(t *testing.T) {
+	bTv := func(b []byte) *TypedValue {
+		return &TypedValue{Value: &TypedValue_BytesVal{BytesVal: b}}
+	}
+	slt := &SchemaLeafType{
+		Type:   "binary",
+		Length: []*SchemaMinMaxType{{Min: &Number{Value: 2}, Max: &Number{Value: 4}}},
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		slt     *SchemaLeafType
+		want    *TypedValue
+		wantErr bool
+	}{
+		{"decoded length within range", "AQID", slt, bTv([]byte{1, 2, 3}), false},
+		// the base64 text is 8 characters long, the decoded value 4 octets
+		{"length applies to octets", "AQIDBA==", slt, bTv([]byte{1, 2, 3, 4}), false},
+		{"too short", "AQ==", slt, nil, true},
+		{"too long", "AQIDBAU=", slt, nil, true},
+		{"line breaks", "AQ\n  ID", slt, bTv([]byte{1, 2, 3}), false},
+		{"invalid base64", "AQI*", slt, nil, true},
+		{"no restriction", "", &SchemaLeafType{Type: "binary"}, bTv([]byte{}), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := TVFromString(tc.slt, tc.value, 0)
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted error, got %v", got)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("TVFromString(%q) = %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+
+	got, err := ConvertJsonValueToTv("AQID", slt)
+	if err != nil || !proto.Equal(got, bTv([]byte{1, 2, 3})) {
+		t.Errorf("ConvertJsonValueToTv() = %v, %v", got, err)
+	}
+	if _, err := ConvertJsonValueToTv(json.Number("1"), slt); err == nil {
+		t.Errorf("ConvertJsonValueToTv() of a number wanted error")
+	}
+	if s := bTv([]byte{1, 2, 3}).ToString(); s != "AQID" {
+		t.Errorf("ToString() = %q, want %q", s, "AQID")
+	}
+}
+
+func TestConvertString_Patterns(t *testing.T) {
+	slt := &SchemaLeafType{
+		Type: "string",
+		Patterns: []*SchemaPattern{
+			{Pattern: `\i\c*`},
+			{Pattern: "mgmt.*", Inverted: true},
+		},
+	}
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"ethernet-1/1", true},
+		{"ethernet-1.1", false},
+		{"1ethernet", true},
+		{"mgmt0", true},
+		{"lo0 ", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := ConvertString(tc.value, slt)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+		})
+	}
+}
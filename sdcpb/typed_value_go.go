@@ -0,0 +1,376 @@
+package sdcpb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// NewTypedValue creates the TypedValue for a Go value. Strings, bools, (unsigned) integers, floats and []byte map
+// to their natural variant, Decimal, *Decimal64 and *big.Rat to decimal_val, time.Time to a RFC 3339 string,
+// net.IP and *net.IPNet to their string form, *IdentityRef to identityref_val and struct{} to empty_val.
+// Slices of any of these become a leaf-list.
+func NewTypedValue(v any) (*TypedValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, fmt.Errorf("cannot create TypedValue from nil")
+	case *TypedValue:
+		return v, nil
+	case string:
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: v}}, nil
+	case bool:
+		return &TypedValue{Value: &TypedValue_BoolVal{BoolVal: v}}, nil
+	case int:
+		return &TypedValue{Value: &TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int8:
+		return &TypedValue{Value: &TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int16:
+		return &TypedValue{Value: &TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int32:
+		return &TypedValue{Value: &TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int64:
+		return &TypedValue{Value: &TypedValue_IntVal{IntVal: v}}, nil
+	case uint:
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint8:
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint16:
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint32:
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint64:
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: v}}, nil
+	case float32:
+		return &TypedValue{Value: &TypedValue_FloatVal{FloatVal: v}}, nil
+	case float64:
+		return &TypedValue{Value: &TypedValue_DoubleVal{DoubleVal: v}}, nil
+	case []byte:
+		return &TypedValue{Value: &TypedValue_BytesVal{BytesVal: v}}, nil
+	case *Decimal64:
+		return &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: v}}, nil
+	case Decimal:
+		d64, err := v.ToDecimal64()
+		if err != nil {
+			return nil, err
+		}
+		return &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: d64}}, nil
+	case *big.Rat:
+		d, err := decimalFromRat(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewTypedValue(d)
+	case time.Time:
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: v.Format(time.RFC3339Nano)}}, nil
+	case net.IP:
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: v.String()}}, nil
+	case *net.IPNet:
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: v.String()}}, nil
+	case *IdentityRef:
+		return &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: v}}, nil
+	case struct{}, *emptypb.Empty:
+		return &TypedValue{Value: &TypedValue_EmptyVal{EmptyVal: &emptypb.Empty{}}}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot create TypedValue from %T", v)
+	}
+	elems := make([]*TypedValue, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		tv, err := NewTypedValue(rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("leaf-list element %d: %w", i, err)
+		}
+		elems = append(elems, tv)
+	}
+	return &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: elems}}}, nil
+}
+
+// decimalFromRat converts the rat into a Decimal, which is only possible if it has a finite decimal representation
+// with at most 18 fraction digits.
+func decimalFromRat(r *big.Rat) (Decimal, error) {
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	var scale uint32
+	for ; scale < 18 && !scaled.IsInt(); scale++ {
+		scaled.Mul(scaled, ten)
+	}
+	if !scaled.IsInt() {
+		return Decimal{}, fmt.Errorf("%s has no exact decimal representation", r.String())
+	}
+	return Decimal{unscaled: new(big.Int).Set(scaled.Num()), scale: scale}, nil
+}
+
+// As returns the value of the TypedValue as T. Numeric conversions are only performed if they are lossless,
+// supported types are the ones of NewTypedValue apart from slices.
+func As[T any](tv *TypedValue) (T, error) {
+	var zero T
+	var result any
+	var err error
+	switch any(zero).(type) {
+	case string:
+		result, err = tv.asString()
+	case bool:
+		if _, ok := tv.GetValue().(*TypedValue_BoolVal); !ok {
+			return zero, tv.asError(zero)
+		}
+		result = tv.GetBoolVal()
+	case int:
+		result, err = asInt[int](tv, math.MinInt, math.MaxInt)
+	case int8:
+		result, err = asInt[int8](tv, math.MinInt8, math.MaxInt8)
+	case int16:
+		result, err = asInt[int16](tv, math.MinInt16, math.MaxInt16)
+	case int32:
+		result, err = asInt[int32](tv, math.MinInt32, math.MaxInt32)
+	case int64:
+		result, err = tv.AsInt64()
+	case uint:
+		result, err = asUint[uint](tv, math.MaxUint)
+	case uint8:
+		result, err = asUint[uint8](tv, math.MaxUint8)
+	case uint16:
+		result, err = asUint[uint16](tv, math.MaxUint16)
+	case uint32:
+		result, err = asUint[uint32](tv, math.MaxUint32)
+	case uint64:
+		result, err = tv.AsUint64()
+	case float32:
+		var f float64
+		f, err = tv.AsFloat64()
+		if err == nil && float64(float32(f)) != f {
+			err = fmt.Errorf("%s does not fit a float32 without loss", tv.ToString())
+		}
+		result = float32(f)
+	case float64:
+		result, err = tv.AsFloat64()
+	case []byte:
+		switch v := tv.GetValue().(type) {
+		case *TypedValue_BytesVal:
+			result = v.BytesVal
+		case *TypedValue_ProtoBytes:
+			result = v.ProtoBytes
+		default:
+			return zero, tv.asError(zero)
+		}
+	case Decimal:
+		result, err = tv.AsDecimal()
+	case *big.Rat:
+		var d Decimal
+		d, err = tv.AsDecimal()
+		result = d.Rat()
+	case time.Time:
+		var s string
+		if s, err = tv.asString(); err == nil {
+			result, err = time.Parse(time.RFC3339Nano, s)
+		}
+	case net.IP:
+		var s string
+		if s, err = tv.asString(); err == nil {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				err = fmt.Errorf("%q is not an IP address", s)
+			}
+			result = ip
+		}
+	case *IdentityRef:
+		if tv.GetIdentityrefVal() == nil {
+			return zero, tv.asError(zero)
+		}
+		result = tv.GetIdentityrefVal()
+	default:
+		return zero, fmt.Errorf("conversion of TypedValue to %T not supported", zero)
+	}
+	if err != nil {
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+func (tv *TypedValue) asError(target any) error {
+	return fmt.Errorf("cannot convert TypedValue of type %T to %T", tv.GetValue(), target)
+}
+
+func (tv *TypedValue) asString() (string, error) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_StringVal:
+		return v.StringVal, nil
+	case *TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	}
+	return "", tv.asError("")
+}
+
+func asInt[T int | int8 | int16 | int32](tv *TypedValue, min, max int64) (T, error) {
+	i, err := tv.AsInt64()
+	if err != nil {
+		return 0, err
+	}
+	if i < min || i > max {
+		return 0, fmt.Errorf("%d does not fit a %T", i, T(0))
+	}
+	return T(i), nil
+}
+
+func asUint[T uint | uint8 | uint16 | uint32](tv *TypedValue, max uint64) (T, error) {
+	u, err := tv.AsUint64()
+	if err != nil {
+		return 0, err
+	}
+	if u > max {
+		return 0, fmt.Errorf("%d does not fit a %T", u, T(0))
+	}
+	return T(u), nil
+}
+
+// AsDecimal returns the numeric value of int, uint, decimal, float and double TypedValues as Decimal.
+// Floats are taken by their shortest decimal representation.
+func (tv *TypedValue) AsDecimal() (Decimal, error) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_IntVal:
+		return NewDecimal(v.IntVal, 0), nil
+	case *TypedValue_UintVal:
+		return Decimal{unscaled: new(big.Int).SetUint64(v.UintVal)}, nil
+	case *TypedValue_DecimalVal:
+		return v.DecimalVal.ToDecimal(), nil
+	case *TypedValue_DoubleVal:
+		return floatToDecimal(v.DoubleVal, 64)
+	case *TypedValue_FloatVal:
+		return floatToDecimal(float64(v.FloatVal), 32)
+	}
+	return Decimal{}, tv.asError(Decimal{})
+}
+
+func floatToDecimal(f float64, bitSize int) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%v has no decimal representation", f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, bitSize))
+}
+
+// AsInt64 returns the value of numeric TypedValues as int64, if it is integral and within the int64 range.
+func (tv *TypedValue) AsInt64() (int64, error) {
+	if v, ok := tv.GetValue().(*TypedValue_IntVal); ok {
+		return v.IntVal, nil
+	}
+	i, err := tv.asInteger()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, fmt.Errorf("%s does not fit an int64", i.String())
+	}
+	return i.Int64(), nil
+}
+
+// AsUint64 returns the value of numeric TypedValues as uint64, if it is integral and within the uint64 range.
+func (tv *TypedValue) AsUint64() (uint64, error) {
+	if v, ok := tv.GetValue().(*TypedValue_UintVal); ok {
+		return v.UintVal, nil
+	}
+	i, err := tv.asInteger()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("%s does not fit an uint64", i.String())
+	}
+	return i.Uint64(), nil
+}
+
+// asInteger returns the numeric value as big.Int, if it is integral.
+func (tv *TypedValue) asInteger() (*big.Int, error) {
+	d, err := tv.AsDecimal()
+	if err != nil {
+		return nil, err
+	}
+	r := d.Rat()
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%s is not an integer", d.String())
+	}
+	return r.Num(), nil
+}
+
+// AsFloat64 returns the value of numeric TypedValues as float64, if it is exactly representable.
+func (tv *TypedValue) AsFloat64() (float64, error) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *TypedValue_FloatVal:
+		return float64(v.FloatVal), nil
+	}
+	d, err := tv.AsDecimal()
+	if err != nil {
+		return 0, err
+	}
+	f, exact := d.Rat().Float64()
+	if !exact {
+		return 0, fmt.Errorf("%s is not exactly representable as float64", d.String())
+	}
+	return f, nil
+}
+
+// ToGo returns the value as a native Go value, e.g. for templating or JSON export. Integers are returned as
+// int64 and uint64, decimals as Decimal, identityrefs in their prefixed form, empty as struct{}, leaf-lists as []any
+// and JSON values decoded into maps and slices.
+func (tv *TypedValue) ToGo() any {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_StringVal:
+		return v.StringVal
+	case *TypedValue_AsciiVal:
+		return v.AsciiVal
+	case *TypedValue_BoolVal:
+		return v.BoolVal
+	case *TypedValue_IntVal:
+		return v.IntVal
+	case *TypedValue_UintVal:
+		return v.UintVal
+	case *TypedValue_DecimalVal:
+		return v.DecimalVal.ToDecimal()
+	case *TypedValue_DoubleVal:
+		return v.DoubleVal
+	case *TypedValue_FloatVal:
+		return v.FloatVal
+	case *TypedValue_BytesVal:
+		return v.BytesVal
+	case *TypedValue_ProtoBytes:
+		return v.ProtoBytes
+	case *TypedValue_AnyVal:
+		return v.AnyVal
+	case *TypedValue_EmptyVal:
+		return struct{}{}
+	case *TypedValue_IdentityrefVal:
+		return tv.ToCanonical(nil)
+	case *TypedValue_JsonVal:
+		return decodeJSONValue(v.JsonVal)
+	case *TypedValue_JsonIetfVal:
+		return decodeJSONValue(v.JsonIetfVal)
+	case *TypedValue_LeaflistVal:
+		result := make([]any, 0, len(v.LeaflistVal.GetElement()))
+		for _, e := range v.LeaflistVal.GetElement() {
+			result = append(result, e.ToGo())
+		}
+		return result
+	}
+	return nil
+}
+
+// decodeJSONValue decodes the JSON document, numbers are kept as json.Number. Invalid documents are returned raw.
+func decodeJSONValue(data []byte) any {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var result any
+	if err := dec.Decode(&result); err != nil {
+		return json.RawMessage(data)
+	}
+	return result
+}
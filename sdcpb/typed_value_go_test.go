@@ -0,0 +1,120 @@
+package sdcpb
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestNewTypedValue(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+	tests := []struct {
+		name    string
+		in      any
+		want    *TypedValue
+		wantErr bool
+	}{
+		{"string", "eth0", &TypedValue{Value: &TypedValue_StringVal{StringVal: "eth0"}}, false},
+		{"int8", int8(-3), &TypedValue{Value: &TypedValue_IntVal{IntVal: -3}}, false},
+		{"uint16", uint16(9000), &TypedValue{Value: &TypedValue_UintVal{UintVal: 9000}}, false},
+		{"float32", float32(1.5), &TypedValue{Value: &TypedValue_FloatVal{FloatVal: 1.5}}, false},
+		{"bytes", []byte{1, 2}, &TypedValue{Value: &TypedValue_BytesVal{BytesVal: []byte{1, 2}}}, false},
+		{"rat", big.NewRat(5, 4), &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 125, Precision: 2}}}, false},
+		{"rat without decimal representation", big.NewRat(1, 3), nil, true},
+		{"time", ts, &TypedValue{Value: &TypedValue_StringVal{StringVal: "2024-05-01T12:30:00.0000005Z"}}, false},
+		{"ip", net.ParseIP("2001:db8::1"), &TypedValue{Value: &TypedValue_StringVal{StringVal: "2001:db8::1"}}, false},
+		{"empty", struct{}{}, &TypedValue{Value: &TypedValue_EmptyVal{EmptyVal: &emptypb.Empty{}}}, false},
+		{"leaf-list", []uint32{1, 2}, &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+			{Value: &TypedValue_UintVal{UintVal: 1}},
+			{Value: &TypedValue_UintVal{UintVal: 2}},
+		}}}}, false},
+		{"unsupported leaf-list element", []any{"a", map[string]string{}}, nil, true},
+		{"unsupported", map[string]string{}, nil, true},
+		{"nil", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTypedValue(tt.in)
+			if tt.wantErr && err == nil {
+				t.Fatalf("wanted error, got %v", got)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("wanted no error, got %v", err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Fatalf("NewTypedValue(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAs(t *testing.T) {
+	intTv := &TypedValue{Value: &TypedValue_IntVal{IntVal: 300}}
+	decTv := &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 4200, Precision: 2}}}
+	fracTv := &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 4250, Precision: 2}}}
+	bigTv := &TypedValue{Value: &TypedValue_UintVal{UintVal: math.MaxUint64}}
+
+	if v, err := As[int16](intTv); err != nil || v != 300 {
+		t.Errorf("As[int16]() = %v, %v, want 300", v, err)
+	}
+	if _, err := As[int8](intTv); err == nil {
+		t.Errorf("As[int8]() of 300 wanted error")
+	}
+	if v, err := As[uint8](decTv); err != nil || v != 42 {
+		t.Errorf("As[uint8]() of 42.00 = %v, %v, want 42", v, err)
+	}
+	if _, err := decTv.AsInt64(); err != nil {
+		t.Errorf("AsInt64() of 42.00 = %v, want no error", err)
+	}
+	if _, err := fracTv.AsInt64(); err == nil {
+		t.Errorf("AsInt64() of 42.50 wanted error")
+	}
+	if _, err := bigTv.AsInt64(); err == nil {
+		t.Errorf("AsInt64() of MaxUint64 wanted error")
+	}
+	if v, err := As[float64](fracTv); err != nil || v != 42.5 {
+		t.Errorf("As[float64]() of 42.50 = %v, %v, want 42.5", v, err)
+	}
+	if _, err := As[float64](bigTv); err == nil {
+		t.Errorf("As[float64]() of MaxUint64 wanted error")
+	}
+	if d, err := As[Decimal](&TypedValue{Value: &TypedValue_FloatVal{FloatVal: 0.1}}); err != nil || d.String() != "0.1" {
+		t.Errorf("As[Decimal]() of float 0.1 = %v, %v, want 0.1", d, err)
+	}
+	if _, err := As[string](intTv); err == nil {
+		t.Errorf("As[string]() of int wanted error")
+	}
+	if ip, err := As[net.IP](&TypedValue{Value: &TypedValue_StringVal{StringVal: "10.0.0.1"}}); err != nil || !ip.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("As[net.IP]() = %v, %v, want 10.0.0.1", ip, err)
+	}
+	ts, err := As[time.Time](&TypedValue{Value: &TypedValue_StringVal{StringVal: "2024-05-01T12:30:00Z"}})
+	if err != nil || !ts.Equal(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("As[time.Time]() = %v, %v", ts, err)
+	}
+	if _, err := As[complex128](intTv); err == nil {
+		t.Errorf("As[complex128]() wanted error")
+	}
+}
+
+func TestTypedValue_ToGo(t *testing.T) {
+	tv := &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+		{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 150, Precision: 2}}},
+		{Value: &TypedValue_UintVal{UintVal: 1 << 40}},
+		{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Value: "ethernetCsmacd", Prefix: "ianaift"}}},
+		{Value: &TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{"mtu": 1500}`)}},
+	}}}}
+	b, err := json.Marshal(tv.ToGo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[1.50,1099511627776,"ianaift:ethernetCsmacd",{"mtu":1500}]`
+	if string(b) != want {
+		t.Errorf("ToGo() marshalled to %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,121 @@
+package sdcpb
+
+import (
+	"cmp"
+	"math/big"
+	"slices"
+)
+
+// EqualSemantic provides equal via CmpSemantic
+func (tv *TypedValue) EqualSemantic(other *TypedValue) bool {
+	return tv.CmpSemantic(other) == 0
+}
+
+// CmpSemantic compares the values the TypedValues represent rather than their encoding.
+// int, uint, decimal, float and double values are compared numerically, string and ascii values
+// by their text and identityrefs by their value qualified by either the module or the prefix.
+// Leaf-lists are compared element wise irrespective of their order.
+// Values of different kinds are ordered by kind, numbers before text before identityrefs before leaf-lists,
+// followed by all other types in the order of Cmp. As an unqualified identityref equals all qualified ones with
+// the same value, the equality of identityrefs is not transitive, Cmp should therefore still be used for sorting.
+func (tv *TypedValue) CmpSemantic(other *TypedValue) int {
+	if tv == nil || other == nil || tv.GetValue() == nil || other.GetValue() == nil {
+		return tv.Cmp(other)
+	}
+	kind := tv.semanticKind()
+	if c := cmp.Compare(kind, other.semanticKind()); c != 0 {
+		return c
+	}
+	switch kind {
+	case semanticKindNumber:
+		a, _ := tv.semanticNumber()
+		b, _ := other.semanticNumber()
+		return a.Cmp(b)
+	case semanticKindText:
+		a, _ := tv.semanticText()
+		b, _ := other.semanticText()
+		return cmp.Compare(a, b)
+	case semanticKindIdentityref:
+		return cmpIdentityRefSemantic(tv.GetIdentityrefVal(), other.GetIdentityrefVal())
+	case semanticKindLeaflist:
+		return cmpLeaflistSemantic(tv.GetLeaflistVal().GetElement(), other.GetLeaflistVal().GetElement())
+	}
+	return tv.Cmp(other)
+}
+
+const (
+	semanticKindNumber = iota
+	semanticKindText
+	semanticKindIdentityref
+	semanticKindLeaflist
+	semanticKindOther
+)
+
+// semanticKind returns the rank of the kind of value for CmpSemantic. Types without a semantic kind, including
+// NaN and infinite floats, rank after all others by their type, so only values of the same type share a kind.
+func (tv *TypedValue) semanticKind() int {
+	if _, ok := tv.semanticNumber(); ok {
+		return semanticKindNumber
+	}
+	if _, ok := tv.semanticText(); ok {
+		return semanticKindText
+	}
+	switch tv.GetValue().(type) {
+	case *TypedValue_IdentityrefVal:
+		return semanticKindIdentityref
+	case *TypedValue_LeaflistVal:
+		return semanticKindLeaflist
+	}
+	return semanticKindOther + tv.typeOrder()
+}
+
+// semanticNumber returns the exact value of numeric TypedValues. Floats are taken by their shortest decimal
+// representation, so a float 0.1 equals a decimal 0.1. NaN and infinite values are not considered numeric.
+func (tv *TypedValue) semanticNumber() (*big.Rat, bool) {
+	d, err := tv.AsDecimal()
+	if err != nil {
+		return nil, false
+	}
+	return d.Rat(), true
+}
+
+// semanticText returns the text of string and ascii TypedValues.
+func (tv *TypedValue) semanticText() (string, bool) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_StringVal:
+		return v.StringVal, true
+	case *TypedValue_AsciiVal:
+		return v.AsciiVal, true
+	}
+	return "", false
+}
+
+// cmpIdentityRefSemantic treats identityrefs with the same value as equal if they share the module or the prefix,
+// or if either of them is not qualified at all.
+func cmpIdentityRefSemantic(a, b *IdentityRef) int {
+	if c := cmp.Compare(a.GetValue(), b.GetValue()); c != 0 {
+		return c
+	}
+	switch {
+	case a.GetModule() == "" && a.GetPrefix() == "", b.GetModule() == "" && b.GetPrefix() == "":
+		return 0
+	case a.GetModule() != "" && a.GetModule() == b.GetModule():
+		return 0
+	case a.GetPrefix() != "" && a.GetPrefix() == b.GetPrefix():
+		return 0
+	}
+	if c := cmp.Compare(a.GetModule(), b.GetModule()); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.GetPrefix(), b.GetPrefix())
+}
+
+// cmpLeaflistSemantic compares the elements of both leaf-lists pairwise after sorting them.
+func cmpLeaflistSemantic(a, b []*TypedValue) int {
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	sa := slices.SortedFunc(slices.Values(a), (*TypedValue).CmpSemantic)
+	sb := slices.SortedFunc(slices.Values(b), (*TypedValue).CmpSemantic)
+	return slices.CompareFunc(sa, sb, (*TypedValue).CmpSemantic)
+}
@@ -0,0 +1,93 @@
+package sdcpb
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestTypedValue_CmpSemantic(t *testing.T) {
+	intTv := func(i int64) *TypedValue { return &TypedValue{Value: &TypedValue_IntVal{IntVal: i}} }
+	uintTv := func(u uint64) *TypedValue { return &TypedValue{Value: &TypedValue_UintVal{UintVal: u}} }
+	decTv := func(digits int64, precision uint32) *TypedValue {
+		return &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: digits, Precision: precision}}}
+	}
+	floatTv := func(f float32) *TypedValue { return &TypedValue{Value: &TypedValue_FloatVal{FloatVal: f}} }
+	doubleTv := func(f float64) *TypedValue { return &TypedValue{Value: &TypedValue_DoubleVal{DoubleVal: f}} }
+	strTv := func(s string) *TypedValue { return &TypedValue{Value: &TypedValue_StringVal{StringVal: s}} }
+	asciiTv := func(s string) *TypedValue { return &TypedValue{Value: &TypedValue_AsciiVal{AsciiVal: s}} }
+	idTv := func(module, prefix, value string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Module: module, Prefix: prefix, Value: value}}}
+	}
+	llTv := func(elems ...*TypedValue) *TypedValue {
+		return &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: elems}}}
+	}
+
+	tests := []struct {
+		name string
+		a, b *TypedValue
+		want int
+	}{
+		{"int and uint", intTv(5), uintTv(5), 0},
+		{"int and decimal", intTv(5), decTv(50, 1), 0},
+		{"uint and double", uintTv(5), doubleTv(5), 0},
+		{"float and decimal", floatTv(0.1), decTv(1, 1), 0},
+		{"negative int below uint", intTv(-1), uintTv(0), -1},
+		{"large uint above int", uintTv(math.MaxUint64), intTv(math.MaxInt64), 1},
+		{"decimal above double", decTv(2501, 3), doubleTv(2.5), 1},
+		{"NaN orders after numbers", doubleTv(math.NaN()), intTv(0), 1},
+		{"NaN orders after text", doubleTv(math.NaN()), strTv("a"), 1},
+		{"infinity orders after numbers", doubleTv(math.Inf(-1)), intTv(0), 1},
+		{"string and ascii", strTv("eth0"), asciiTv("eth0"), 0},
+		{"string and ascii differ", strTv("eth0"), asciiTv("eth1"), -1},
+		{"identityref by module", idTv("iana-if-type", "ianaift", "ethernetCsmacd"), idTv("iana-if-type", "", "ethernetCsmacd"), 0},
+		{"identityref by prefix", idTv("", "ianaift", "ethernetCsmacd"), idTv("iana-if-type", "ianaift", "ethernetCsmacd"), 0},
+		{"identityref same module other prefix", idTv("iana-if-type", "ianaift", "ethernetCsmacd"), idTv("iana-if-type", "if", "ethernetCsmacd"), 0},
+		{"identityref same prefix other module", idTv("a", "p", "x"), idTv("b", "p", "x"), 0},
+		{"identityref module and prefix only", idTv("iana-if-type", "", "ethernetCsmacd"), idTv("", "ianaift", "ethernetCsmacd"), 1},
+		{"identityref unqualified and module", idTv("", "", "ethernetCsmacd"), idTv("iana-if-type", "", "ethernetCsmacd"), 0},
+		{"identityref unqualified and prefix", idTv("", "", "ethernetCsmacd"), idTv("", "ianaift", "ethernetCsmacd"), 0},
+		{"identityref unqualified and qualified", idTv("", "", "ethernetCsmacd"), idTv("iana-if-type", "ianaift", "ethernetCsmacd"), 0},
+		{"identityref unqualified other value", idTv("", "", "a"), idTv("iana-if-type", "ianaift", "b"), -1},
+		{"identityref other module", idTv("a", "", "x"), idTv("b", "", "x"), -1},
+		{"identityref other value", idTv("a", "", "x"), idTv("a", "", "y"), -1},
+		{"leaflist order and encoding", llTv(intTv(2), uintTv(1)), llTv(decTv(10, 1), uintTv(2)), 0},
+		{"leaflist length", llTv(intTv(1)), llTv(intTv(1), intTv(2)), -1},
+		{"numbers before text", strTv("5"), intTv(5), 1},
+		{"text before identityrefs", asciiTv("z"), idTv("", "", "a"), -1},
+		{"identityrefs before leaflists", idTv("", "", "a"), llTv(), -1},
+		{"leaflists before bools", llTv(intTv(1)), &TypedValue{Value: &TypedValue_BoolVal{BoolVal: false}}, -1},
+		{"mixed leaflist in different orders", llTv(strTv("a"), intTv(1), idTv("m", "", "x"), decTv(5, 1)), llTv(idTv("m", "p", "x"), doubleTv(0.5), asciiTv("a"), uintTv(1)), 0},
+		{"nil", nil, intTv(5), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.CmpSemantic(tt.b); got != tt.want {
+				t.Errorf("CmpSemantic() = %d, want %d", got, tt.want)
+			}
+			if got := tt.b.CmpSemantic(tt.a); got != -tt.want {
+				t.Errorf("reversed CmpSemantic() = %d, want %d", got, -tt.want)
+			}
+			if got := tt.a.EqualSemantic(tt.b); got != (tt.want == 0) {
+				t.Errorf("EqualSemantic() = %v, want %v", got, tt.want == 0)
+			}
+		})
+	}
+
+	// sorting mixed kinds gives the same order irrespective of the input order
+	mixed := []*TypedValue{strTv("b"), idTv("", "p", "x"), intTv(2), asciiTv("a"), llTv(intTv(1)), uintTv(1), decTv(15, 1)}
+	want := slices.SortedFunc(slices.Values(mixed), (*TypedValue).CmpSemantic)
+	for i := range mixed {
+		rotated := append(slices.Clone(mixed[i:]), mixed[:i]...)
+		slices.Reverse(rotated)
+		got := slices.SortedFunc(slices.Values(rotated), (*TypedValue).CmpSemantic)
+		if slices.CompareFunc(got, want, (*TypedValue).Cmp) != 0 {
+			t.Errorf("sorting %v = %v, want %v", rotated, got, want)
+		}
+	}
+
+	// the strict ordering is not affected
+	if intTv(5).Equal(uintTv(5)) {
+		t.Errorf("Equal() of int and uint 5 = true, want false")
+	}
+}
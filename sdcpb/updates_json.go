@@ -0,0 +1,478 @@
+package sdcpb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SchemaLookupFunc returns the schema element of the node referenced by the given path.
+// The path may or may not carry the keys of list elements, implementations should not depend on them.
+// A nil SchemaElem without an error indicates that no schema information is available for the path.
+type SchemaLookupFunc func(p *Path) (*SchemaElem, error)
+
+// jsonTreeNode is the intermediate representation used to build the nested json document.
+// A node is either a container (or list entry), a list or a leaf.
+type jsonTreeNode struct {
+	module string
+	schema *SchemaElem
+
+	// container and list entry content
+	children map[string]*jsonTreeNode
+	keys     map[string]any
+	raw      map[string]any
+
+	// list content, entries are kept in insertion order
+	entries  []*jsonTreeNode
+	entryIdx map[string]*jsonTreeNode
+
+	// leaf content
+	value    any
+	hasValue bool
+}
+
+type jsonTreeBuilder struct {
+	ietf   bool
+	lookup SchemaLookupFunc
+	root   *jsonTreeNode
+}
+
+// UpdatesToJSONTree converts the flat list of updates into a nested json structure.
+// If ietf is set, the result follows RFC 7951 (JSON_IETF), with module qualified member names and
+// 64-bit numbers encoded as strings, otherwise plain JSON with unqualified member names is generated.
+// Module names are taken from prefixed PathElem names ("module:name") or, if lookup is provided,
+// from the schema. The lookup is optional and additionally used to determine the json type of list keys and leafs.
+func UpdatesToJSONTree(updates []*Update, ietf bool, lookup SchemaLookupFunc) (map[string]any, error) {
+	b := &jsonTreeBuilder{
+		ietf:   ietf,
+		lookup: lookup,
+		root:   &jsonTreeNode{},
+	}
+	for _, u := range updates {
+		if err := b.addUpdate(u); err != nil {
+			return nil, fmt.Errorf("update %s: %w", u.GetPath().ToXPath(false), err)
+		}
+	}
+	return b.root.toContainer(b.ietf, ""), nil
+}
+
+// UpdatesToJSON converts the flat list of updates into a json document, see UpdatesToJSONTree.
+func UpdatesToJSON(updates []*Update, ietf bool, lookup SchemaLookupFunc) ([]byte, error) {
+	tree, err := UpdatesToJSONTree(updates, ietf, lookup)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tree)
+}
+
+// splitModuleName splits a "module:name" formatted PathElem name into its module and name.
+func splitModuleName(s string) (string, string) {
+	if module, name, found := strings.Cut(s, ":"); found {
+		return module, name
+	}
+	return "", s
+}
+
+func (b *jsonTreeBuilder) addUpdate(u *Update) error {
+	n := b.root
+	elems := u.GetPath().GetElem()
+	current := &Path{
+		Origin:      u.GetPath().GetOrigin(),
+		Target:      u.GetPath().GetTarget(),
+		IsRootBased: u.GetPath().GetIsRootBased(),
+	}
+	for _, pe := range elems {
+		current = current.CopyPathAddElem(pe)
+		module, name := splitModuleName(pe.GetName())
+		child, err := b.child(n, name, module, current)
+		if err != nil {
+			return err
+		}
+		if len(pe.GetKey()) > 0 {
+			child, err = b.listEntry(child, pe)
+			if err != nil {
+				return err
+			}
+		}
+		n = child
+	}
+	return b.setValue(n, u.GetValue())
+}
+
+// child returns the child node with the given name, creating it if it does not exist yet.
+func (b *jsonTreeBuilder) child(n *jsonTreeNode, name string, module string, p *Path) (*jsonTreeNode, error) {
+	if n.hasValue {
+		return nil, fmt.Errorf("%s is a leaf and can not have children", p.ToXPath(false))
+	}
+	if len(n.entries) > 0 {
+		return nil, fmt.Errorf("%s is a list and requires keys", p.ToXPath(false))
+	}
+	if c, exists := n.children[name]; exists {
+		if c.module == "" {
+			c.module = module
+		}
+		return c, nil
+	}
+	c := &jsonTreeNode{module: module}
+	if b.lookup != nil {
+		schema, err := b.lookup(p)
+		if err != nil {
+			return nil, err
+		}
+		c.schema = schema
+		if c.module == "" {
+			c.module = schema.ModuleName()
+		}
+	}
+	if n.children == nil {
+		n.children = map[string]*jsonTreeNode{}
+	}
+	n.children[name] = c
+	return c, nil
+}
+
+// listEntry returns the list entry identified by the keys of the PathElem, creating it if it does not exist yet.
+func (b *jsonTreeBuilder) listEntry(list *jsonTreeNode, pe *PathElem) (*jsonTreeNode, error) {
+	if list.hasValue || len(list.children) > 0 {
+		return nil, fmt.Errorf("%s is not a list", pe.GetName())
+	}
+	id := pathElemKeyString(pe)
+	if e, exists := list.entryIdx[id]; exists {
+		return e, nil
+	}
+	e := &jsonTreeNode{
+		module: list.module,
+		schema: list.schema,
+		keys:   make(map[string]any, len(pe.GetKey())),
+	}
+	for k, v := range pe.GetKey() {
+		_, k = splitModuleName(k)
+		e.keys[k] = b.keyValue(list.schema, k, v)
+	}
+	if list.entryIdx == nil {
+		list.entryIdx = map[string]*jsonTreeNode{}
+	}
+	list.entryIdx[id] = e
+	list.entries = append(list.entries, e)
+	return e, nil
+}
+
+// keyValue converts the string value of a key into its json representation, using the key type
+// from the schema if available.
+func (b *jsonTreeBuilder) keyValue(schema *SchemaElem, name string, value string) any {
+	for _, k := range schema.GetContainer().GetKeys() {
+		if k.GetName() != name {
+			continue
+		}
+		tv, err := TVFromString(k.GetType(), value, 0)
+		if err != nil {
+			break
+		}
+		jv, err := jsonValue(tv, k.GetType(), b.ietf)
+		if err != nil {
+			break
+		}
+		return jv
+	}
+	return value
+}
+
+func (b *jsonTreeBuilder) setValue(n *jsonTreeNode, tv *TypedValue) error {
+	switch tv.GetValue().(type) {
+	case *TypedValue_JsonVal, *TypedValue_JsonIetfVal:
+		raw := tv.GetJsonVal()
+		if raw == nil {
+			raw = tv.GetJsonIetfVal()
+		}
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return n.setLeaf(v)
+		}
+		if n.hasValue || len(n.entries) > 0 {
+			return fmt.Errorf("json object value on a leaf or list")
+		}
+		if n.raw == nil {
+			n.raw = map[string]any{}
+		}
+		for k, v := range obj {
+			n.raw[k] = v
+		}
+		return nil
+	case nil:
+		// presence containers and empty updates
+		return nil
+	}
+	v, err := jsonValue(tv, n.schema.LeafType(), b.ietf)
+	if err != nil {
+		return err
+	}
+	return n.setLeaf(v)
+}
+
+func (n *jsonTreeNode) setLeaf(v any) error {
+	if len(n.children) > 0 || len(n.entries) > 0 || n.raw != nil {
+		return fmt.Errorf("scalar value on a container or list")
+	}
+	n.value = v
+	n.hasValue = true
+	return nil
+}
+
+// toContainer renders a container or list entry node, that belongs to the given module. Member names
+// are module qualified for ietf, whenever the module of the member differs from the module of the container.
+func (n *jsonTreeNode) toContainer(ietf bool, module string) map[string]any {
+	result := make(map[string]any, len(n.raw)+len(n.keys)+len(n.children))
+	for k, v := range n.raw {
+		result[k] = v
+	}
+	for k, v := range n.keys {
+		result[k] = v
+	}
+	for name, c := range n.children {
+		if ietf && c.module != "" && c.module != module {
+			name = c.module + ":" + name
+		}
+		result[name] = c.toJSON(ietf, module)
+	}
+	return result
+}
+
+func (n *jsonTreeNode) toJSON(ietf bool, parentModule string) any {
+	module := n.module
+	if module == "" {
+		module = parentModule
+	}
+	switch {
+	case n.hasValue:
+		return n.value
+	case len(n.entries) > 0:
+		result := make([]any, 0, len(n.entries))
+		for _, e := range n.entries {
+			result = append(result, e.toContainer(ietf, module))
+		}
+		return result
+	}
+	return n.toContainer(ietf, module)
+}
+
+// jsonValue returns the json representation of the TypedValue. slt is optional and used to
+// determine whether integers need to be encoded as strings in ietf mode.
+func jsonValue(tv *TypedValue, slt *SchemaLeafType, ietf bool) (any, error) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_StringVal:
+		return v.StringVal, nil
+	case *TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *TypedValue_IntVal:
+		if ietf && jsonIetfIntAsString(slt, v.IntVal >= math.MinInt32 && v.IntVal <= math.MaxInt32) {
+			return strconv.FormatInt(v.IntVal, 10), nil
+		}
+		return v.IntVal, nil
+	case *TypedValue_UintVal:
+		if ietf && jsonIetfIntAsString(slt, v.UintVal <= math.MaxUint32) {
+			return strconv.FormatUint(v.UintVal, 10), nil
+		}
+		return v.UintVal, nil
+	case *TypedValue_DecimalVal:
+		if ietf {
+			return tv.ToString(), nil
+		}
+		return json.Number(tv.ToString()), nil
+	case *TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *TypedValue_FloatVal:
+		return float64(v.FloatVal), nil
+	case *TypedValue_BytesVal:
+		return base64.StdEncoding.EncodeToString(v.BytesVal), nil
+	case *TypedValue_EmptyVal:
+		if ietf {
+			return []any{nil}, nil
+		}
+		return map[string]any{}, nil
+	case *TypedValue_IdentityrefVal:
+		if ietf && v.IdentityrefVal.GetModule() != "" {
+			return v.IdentityrefVal.JsonIetfString(), nil
+		}
+		return v.IdentityrefVal.GetValue(), nil
+	case *TypedValue_LeaflistVal:
+		result := make([]any, 0, len(v.LeaflistVal.GetElement()))
+		for _, e := range v.LeaflistVal.GetElement() {
+			ev, err := jsonValue(e, slt, ietf)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, ev)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("json encoding of %T not supported", tv.GetValue())
+}
+
+// jsonIetfIntAsString determines if an integer is encoded as a string as per RFC 7951 section 6.1.
+// Without (conclusive) type information, integers exceeding the 32-bit range are encoded as string.
+func jsonIetfIntAsString(slt *SchemaLeafType, fits32 bool) bool {
+	switch slt.GetType() {
+	case "int64", "uint64":
+		return true
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		return false
+	case "leafref":
+		return jsonIetfIntAsString(slt.GetLeafrefTargetType(), fits32)
+	}
+	return !fits32
+}
+
+// JSONToUpdates decodes a JSON or JSON_IETF document into a flat list of updates, one per leaf and leaf-list.
+// The document represents the subtree under basePath, which may be nil for the root.
+// Module qualified member names are accepted and stripped from the resulting paths. The lookup is required
+// to distinguish lists, leaf-lists and leafs and to convert the leaf values via ConvertJsonValueToTv.
+func JSONToUpdates(basePath *Path, data []byte, lookup SchemaLookupFunc) ([]*Update, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// keep numbers as json.Number to not lose precision on 64-bit values
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return JSONTreeToUpdates(basePath, tree, lookup)
+}
+
+// JSONTreeToUpdates converts an already unmarshalled json document into a flat list of updates, see JSONToUpdates.
+func JSONTreeToUpdates(basePath *Path, tree any, lookup SchemaLookupFunc) ([]*Update, error) {
+	if lookup == nil {
+		return nil, fmt.Errorf("schema lookup function must not be nil")
+	}
+	obj, ok := tree.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected json object, got %T", tree)
+	}
+	if basePath == nil {
+		basePath = &Path{IsRootBased: true}
+	}
+	d := &jsonTreeDecoder{lookup: lookup}
+	if err := d.decodeObject(basePath, obj); err != nil {
+		return nil, err
+	}
+	return d.updates, nil
+}
+
+type jsonTreeDecoder struct {
+	lookup  SchemaLookupFunc
+	updates []*Update
+}
+
+// childPath returns the path of the given member below parent, with the module prefixes of the new element stripped.
+func childPath(parent *Path, member string, keys map[string]string) *Path {
+	pe := NewPathElem(member, keys)
+	(&Path{Elem: []*PathElem{pe}}).StripPathElemPrefixPath()
+	return parent.CopyPathAddElem(pe)
+}
+
+func (d *jsonTreeDecoder) decodeObject(p *Path, obj map[string]any) error {
+	// iterate in a stable order to produce deterministic updates
+	for _, member := range slices.Sorted(maps.Keys(obj)) {
+		if err := d.decodeMember(p, member, obj[member]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *jsonTreeDecoder) decodeMember(parent *Path, member string, v any) error {
+	p := childPath(parent, member, nil)
+	schema, err := d.lookup(p)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return fmt.Errorf("unknown element %s", p.ToXPath(false))
+	}
+	switch s := schema.GetSchema().(type) {
+	case *SchemaElem_Container:
+		if len(s.Container.GetKeys()) > 0 {
+			return d.decodeList(parent, member, s.Container, v)
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected json object, got %T", p.ToXPath(false), v)
+		}
+		if len(obj) == 0 && s.Container.GetIsPresence() {
+			d.updates = append(d.updates, &Update{Path: p, Value: &TypedValue{Value: &TypedValue_EmptyVal{}}})
+			return nil
+		}
+		return d.decodeObject(p, obj)
+	case *SchemaElem_Leaflist:
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected json array for leaf-list, got %T", p.ToXPath(false), v)
+		}
+		elements := make([]*TypedValue, 0, len(arr))
+		for _, e := range arr {
+			tv, err := ConvertJsonValueToTv(e, s.Leaflist.GetType())
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.ToXPath(false), err)
+			}
+			elements = append(elements, tv)
+		}
+		d.updates = append(d.updates, &Update{
+			Path:  p,
+			Value: &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: elements}}},
+		})
+		return nil
+	case *SchemaElem_Field:
+		tv, err := ConvertJsonValueToTv(v, s.Field.GetType())
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.ToXPath(false), err)
+		}
+		d.updates = append(d.updates, &Update{Path: p, Value: tv})
+		return nil
+	}
+	return fmt.Errorf("%s: unknown schema element type", p.ToXPath(false))
+}
+
+func (d *jsonTreeDecoder) decodeList(parent *Path, member string, schema *ContainerSchema, v any) error {
+	arr, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("%s: expected json array for list, got %T", childPath(parent, member, nil).ToXPath(false), v)
+	}
+	for _, e := range arr {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected json object as list entry, got %T", childPath(parent, member, nil).ToXPath(false), e)
+		}
+		// index the members by their unqualified names to find the keys
+		unqualified := make(map[string]any, len(entry))
+		for k, v := range entry {
+			_, name := splitModuleName(k)
+			unqualified[name] = v
+		}
+		keys := make(map[string]string, len(schema.GetKeys()))
+		for _, k := range schema.GetKeys() {
+			kv, exists := unqualified[k.GetName()]
+			if !exists {
+				return fmt.Errorf("%s: list entry is missing key %q", childPath(parent, member, nil).ToXPath(false), k.GetName())
+			}
+			tv, err := ConvertJsonValueToTv(kv, k.GetType())
+			if err != nil {
+				return fmt.Errorf("%s: key %q: %w", childPath(parent, member, nil).ToXPath(false), k.GetName(), err)
+			}
+			keys[k.GetName()] = tv.ToString()
+		}
+		if err := d.decodeObject(childPath(parent, member, keys), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
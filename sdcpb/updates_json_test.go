@@ -0,0 +1,261 @@
+package sdcpb
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func testSchemaLookup(schemas map[string]*SchemaElem) SchemaLookupFunc {
+	return func(p *Path) (*SchemaElem, error) {
+		return schemas[p.DeepCopy().StripPathElemPrefixPath().ToXPath(true)], nil
+	}
+}
+
+func TestUpdatesToJSON(t *testing.T) {
+	updates := []*Update{
+		{
+			Path:  mustParsePath(t, "/srl_nokia-interfaces:interface[name=ethernet-1/1]/description"),
+			Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "uplink"}},
+		},
+		{
+			Path:  mustParsePath(t, "/srl_nokia-interfaces:interface[name=ethernet-1/1]/mtu"),
+			Value: &TypedValue{Value: &TypedValue_UintVal{UintVal: 9000}},
+		},
+		{
+			Path:  mustParsePath(t, "/srl_nokia-interfaces:interface[name=ethernet-1/1]/srl_nokia-if-ext:counter"),
+			Value: &TypedValue{Value: &TypedValue_UintVal{UintVal: 5}},
+		},
+		{
+			Path:  mustParsePath(t, "/srl_nokia-interfaces:interface[name=ethernet-1/2]/description"),
+			Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "downlink"}},
+		},
+		{
+			Path: mustParsePath(t, "/srl_nokia-system:system/dns/server"),
+			Value: &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+				{Value: &TypedValue_StringVal{StringVal: "1.1.1.1"}},
+				{Value: &TypedValue_StringVal{StringVal: "8.8.8.8"}},
+			}}}},
+		},
+		{
+			Path:  mustParsePath(t, "/srl_nokia-system:system/type"),
+			Value: &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Value: "router", Prefix: "srl-sys", Module: "srl_nokia-system"}}},
+		},
+		{
+			Path:  mustParsePath(t, "/srl_nokia-system:system/load"),
+			Value: &TypedValue{Value: &TypedValue_DecimalVal{DecimalVal: &Decimal64{Digits: 1234, Precision: 2}}},
+		},
+		{
+			Path:  mustParsePath(t, "/srl_nokia-system:system/enabled"),
+			Value: &TypedValue{Value: &TypedValue_EmptyVal{}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		ietf   bool
+		lookup SchemaLookupFunc
+		want   string
+	}{
+		{
+			name: "json",
+			ietf: false,
+			want: `{
+				"interface": [
+					{"name": "ethernet-1/1", "description": "uplink", "mtu": 9000, "counter": 5},
+					{"name": "ethernet-1/2", "description": "downlink"}
+				],
+				"system": {"dns": {"server": ["1.1.1.1", "8.8.8.8"]}, "type": "router", "load": 12.34, "enabled": {}}
+			}`,
+		},
+		{
+			name: "json_ietf",
+			ietf: true,
+			want: `{
+				"srl_nokia-interfaces:interface": [
+					{"name": "ethernet-1/1", "description": "uplink", "mtu": 9000, "srl_nokia-if-ext:counter": 5},
+					{"name": "ethernet-1/2", "description": "downlink"}
+				],
+				"srl_nokia-system:system": {"dns": {"server": ["1.1.1.1", "8.8.8.8"]}, "type": "srl_nokia-system:router", "load": "12.34", "enabled": [null]}
+			}`,
+		},
+		{
+			name: "json_ietf with schema",
+			ietf: true,
+			lookup: testSchemaLookup(map[string]*SchemaElem{
+				"/interface/mtu": {Schema: &SchemaElem_Field{Field: &LeafSchema{Type: &SchemaLeafType{Type: "uint64"}}}},
+			}),
+			want: `{
+				"srl_nokia-interfaces:interface": [
+					{"name": "ethernet-1/1", "description": "uplink", "mtu": "9000", "srl_nokia-if-ext:counter": 5},
+					{"name": "ethernet-1/2", "description": "downlink"}
+				],
+				"srl_nokia-system:system": {"dns": {"server": ["1.1.1.1", "8.8.8.8"]}, "type": "srl_nokia-system:router", "load": "12.34", "enabled": [null]}
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpdatesToJSON(updates, tt.ietf, tt.lookup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, got, []byte(tt.want))
+		})
+	}
+}
+
+func TestUpdatesToJSON_SchemaTypedKeys(t *testing.T) {
+	lookup := testSchemaLookup(map[string]*SchemaElem{
+		"/network-instance/protocols/bgp/neighbor": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name:       "neighbor",
+			ModuleName: "bgp",
+			Keys:       []*LeafSchema{{Name: "id", Type: &SchemaLeafType{Type: "uint32"}}},
+		}}},
+	})
+	updates := []*Update{
+		{
+			Path:  mustParsePath(t, "/network-instance[name=default]/protocols/bgp/neighbor[id=1]/description"),
+			Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "peer"}},
+		},
+	}
+	got, err := UpdatesToJSON(updates, true, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"network-instance": [{"name": "default", "protocols": {"bgp": {"bgp:neighbor": [{"id": 1, "description": "peer"}]}}}]}`
+	assertJSONEqual(t, got, []byte(want))
+}
+
+func TestUpdatesToJSON_Conflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates []*Update
+	}{
+		{
+			name: "leaf with children",
+			updates: []*Update{
+				{Path: mustParsePath(t, "/a/b"), Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "x"}}},
+				{Path: mustParsePath(t, "/a/b/c"), Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "y"}}},
+			},
+		},
+		{
+			name: "list without keys",
+			updates: []*Update{
+				{Path: mustParsePath(t, "/a[k=1]/b"), Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "x"}}},
+				{Path: mustParsePath(t, "/a/b"), Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "y"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UpdatesToJSON(tt.updates, false, nil); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want []byte) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid json %s: %v", got, err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("invalid json %s: %v", want, err)
+	}
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	if string(gb) != string(wb) {
+		t.Errorf("got json\n%s\nwant\n%s", gb, wb)
+	}
+}
+
+func TestJSONToUpdates(t *testing.T) {
+	lookup := testSchemaLookup(map[string]*SchemaElem{
+		"/interface": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "interface",
+			Keys: []*LeafSchema{{Name: "name", Type: &SchemaLeafType{Type: "string"}}},
+		}}},
+		"/interface/name":        {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "name", Type: &SchemaLeafType{Type: "string"}}}},
+		"/interface/mtu":         {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "mtu", Type: &SchemaLeafType{Type: "uint64"}}}},
+		"/interface/load":        {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "load", Type: &SchemaLeafType{Type: "decimal64"}}}},
+		"/interface/vlan-tagged": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "vlan-tagged", Type: &SchemaLeafType{Type: "empty"}}}},
+		"/system":                {Schema: &SchemaElem_Container{Container: &ContainerSchema{Name: "system"}}},
+		"/system/dns":            {Schema: &SchemaElem_Container{Container: &ContainerSchema{Name: "dns", IsPresence: true}}},
+		"/system/server":         {Schema: &SchemaElem_Leaflist{Leaflist: &LeafListSchema{Name: "server", Type: &SchemaLeafType{Type: "string"}}}},
+	})
+
+	tests := []struct {
+		name    string
+		doc     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "json_ietf",
+			doc: `{
+				"mod:interface": [
+					{"name": "ethernet-1/1", "mtu": "9000", "load": "12.34", "vlan-tagged": [null]},
+					{"mod:name": "ethernet-1/2"}
+				],
+				"mod:system": {"server": ["1.1.1.1", "8.8.8.8"], "dns": {}}
+			}`,
+			want: []string{
+				"/interface[name=ethernet-1/1]/load: 12.34",
+				"/interface[name=ethernet-1/1]/mtu: 9000",
+				"/interface[name=ethernet-1/1]/name: ethernet-1/1",
+				"/interface[name=ethernet-1/1]/vlan-tagged: {}",
+				"/interface[name=ethernet-1/2]/name: ethernet-1/2",
+				"/system/dns: {}",
+				"/system/server: 1.1.1.1,8.8.8.8",
+			},
+		},
+		{
+			name: "json",
+			doc:  `{"interface": [{"name": "ethernet-1/1", "mtu": 18446744073709551615}]}`,
+			want: []string{
+				"/interface[name=ethernet-1/1]/mtu: 18446744073709551615",
+				"/interface[name=ethernet-1/1]/name: ethernet-1/1",
+			},
+		},
+		{
+			name:    "unknown element",
+			doc:     `{"foo": "bar"}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing list key",
+			doc:     `{"interface": [{"mtu": 1500}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "list is not an array",
+			doc:     `{"interface": {"name": "ethernet-1/1"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates, err := JSONToUpdates(nil, []byte(tt.doc), lookup)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(updates))
+			for _, u := range updates {
+				got = append(got, u.GetPath().ToXPath(false)+": "+u.GetValue().ToString())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("JSONToUpdates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
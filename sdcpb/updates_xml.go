@@ -0,0 +1,407 @@
+package sdcpb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// NetconfBaseNamespace is the namespace of the NETCONF base:1.0 protocol, used for the operation attribute.
+	NetconfBaseNamespace = "urn:ietf:params:xml:ns:netconf:base:1.0"
+
+	netconfBasePrefix = "nc"
+	xmlConfigElement  = "config"
+)
+
+// xmlTreeNode is the intermediate representation used to build the NETCONF edit tree.
+type xmlTreeNode struct {
+	name      string
+	namespace string
+	schema    *SchemaElem
+
+	keys      map[string]string
+	children  []*xmlTreeNode
+	childIdx  map[string]*xmlTreeNode
+	text      string
+	hasText   bool
+	operation string
+	// leafList marks the node as a placeholder for the individual leaf-list elements held as children
+	leafList bool
+}
+
+type xmlTreeBuilder struct {
+	opts   *NetconfOptions
+	lookup SchemaLookupFunc
+	root   *xmlTreeNode
+}
+
+// UpdatesToXML converts the updates and deletes into a NETCONF <config> edit tree.
+// The NetconfOptions define whether namespaces are included (include_ns), whether the operation attribute
+// is qualified with the base:1.0 namespace (operation_with_ns) and if deletes use the remove instead of the
+// delete operation (use_operation_remove). opts may be nil, to use the defaults.
+// The lookup is optional, it provides the namespaces as well as the order of list keys.
+func UpdatesToXML(updates []*Update, deletes []*Path, opts *NetconfOptions, lookup SchemaLookupFunc) ([]byte, error) {
+	b := &xmlTreeBuilder{
+		opts:   opts,
+		lookup: lookup,
+		root:   &xmlTreeNode{name: xmlConfigElement},
+	}
+	operation := "delete"
+	if opts.GetUseOperationRemove() {
+		operation = "remove"
+	}
+	for _, p := range deletes {
+		n, err := b.node(p)
+		if err != nil {
+			return nil, fmt.Errorf("delete %s: %w", p.ToXPath(false), err)
+		}
+		n.operation = operation
+	}
+	for _, u := range updates {
+		if err := b.addUpdate(u); err != nil {
+			return nil, fmt.Errorf("update %s: %w", u.GetPath().ToXPath(false), err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	enc := xml.NewEncoder(buf)
+	if err := b.encode(enc, b.root, ""); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// node returns the node of the given path, creating all missing nodes on the way.
+func (b *xmlTreeBuilder) node(p *Path) (*xmlTreeNode, error) {
+	n := b.root
+	current := &Path{
+		Origin:      p.GetOrigin(),
+		Target:      p.GetTarget(),
+		IsRootBased: p.GetIsRootBased(),
+	}
+	for _, pe := range p.GetElem() {
+		current = current.CopyPathAddElem(pe)
+		if n.hasText {
+			return nil, fmt.Errorf("%s is a leaf and can not have children", current.ToXPath(false))
+		}
+		_, name := splitModuleName(pe.GetName())
+		id := name + "\x00" + pathElemKeyString(pe)
+		if c, exists := n.childIdx[id]; exists {
+			n = c
+			continue
+		}
+		c := &xmlTreeNode{name: name}
+		if len(pe.GetKey()) > 0 {
+			c.keys = make(map[string]string, len(pe.GetKey()))
+			for k, v := range pe.GetKey() {
+				_, k = splitModuleName(k)
+				c.keys[k] = v
+			}
+		}
+		if b.lookup != nil {
+			schema, err := b.lookup(current)
+			if err != nil {
+				return nil, err
+			}
+			c.schema = schema
+			c.namespace = schemaNamespace(schema)
+		}
+		if n.childIdx == nil {
+			n.childIdx = map[string]*xmlTreeNode{}
+		}
+		n.childIdx[id] = c
+		n.children = append(n.children, c)
+		n = c
+	}
+	return n, nil
+}
+
+func schemaNamespace(s *SchemaElem) string {
+	switch x := s.GetSchema().(type) {
+	case *SchemaElem_Container:
+		return x.Container.GetNamespace()
+	case *SchemaElem_Field:
+		return x.Field.GetNamespace()
+	case *SchemaElem_Leaflist:
+		return x.Leaflist.GetNamespace()
+	}
+	return ""
+}
+
+func schemaPrefix(s *SchemaElem) string {
+	switch x := s.GetSchema().(type) {
+	case *SchemaElem_Container:
+		return x.Container.GetPrefix()
+	case *SchemaElem_Field:
+		return x.Field.GetPrefix()
+	case *SchemaElem_Leaflist:
+		return x.Leaflist.GetPrefix()
+	}
+	return ""
+}
+
+func (b *xmlTreeBuilder) addUpdate(u *Update) error {
+	n, err := b.node(u.GetPath())
+	if err != nil {
+		return err
+	}
+	tv := u.GetValue()
+	switch tv.GetValue().(type) {
+	case nil, *TypedValue_EmptyVal:
+		// presence containers and empty leafs
+		return nil
+	case *TypedValue_LeaflistVal:
+		if len(n.children) > 0 || n.hasText {
+			return fmt.Errorf("leaf-list value on a container or leaf")
+		}
+		// leaf-lists are rendered as repeated elements, n becomes a placeholder for the elements
+		n.leafList = true
+		for _, e := range tv.GetLeaflistVal().GetElement() {
+			text, err := xmlValue(e)
+			if err != nil {
+				return err
+			}
+			n.children = append(n.children, &xmlTreeNode{name: n.name, text: text, hasText: true})
+		}
+		return nil
+	}
+	if len(n.children) > 0 {
+		return fmt.Errorf("scalar value on a container or list")
+	}
+	text, err := xmlValue(tv)
+	if err != nil {
+		return err
+	}
+	n.text = text
+	n.hasText = true
+	return nil
+}
+
+// xmlValue returns the XML text representation of a scalar TypedValue.
+func xmlValue(tv *TypedValue) (string, error) {
+	switch v := tv.GetValue().(type) {
+	case *TypedValue_BytesVal:
+		return base64.StdEncoding.EncodeToString(v.BytesVal), nil
+	case *TypedValue_IdentityrefVal:
+		if v.IdentityrefVal.GetPrefix() != "" {
+			return v.IdentityrefVal.YangString(), nil
+		}
+		return v.IdentityrefVal.GetValue(), nil
+	case *TypedValue_AnyVal, *TypedValue_JsonVal, *TypedValue_JsonIetfVal, *TypedValue_ProtoBytes:
+		return "", fmt.Errorf("xml encoding of %T not supported", tv.GetValue())
+	}
+	return tv.ToString(), nil
+}
+
+// sortedKeyNames returns the key names of the node in schema order, if known, or alphabetically otherwise.
+func (n *xmlTreeNode) sortedKeyNames() []string {
+	result := make([]string, 0, len(n.keys))
+	for _, k := range n.schema.GetContainer().GetKeys() {
+		if _, exists := n.keys[k.GetName()]; exists {
+			result = append(result, k.GetName())
+		}
+	}
+	if len(result) == len(n.keys) {
+		return result
+	}
+	result = result[:0]
+	for k := range n.keys {
+		result = append(result, k)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func (b *xmlTreeBuilder) encode(enc *xml.Encoder, n *xmlTreeNode, parentNamespace string) error {
+	if n.leafList {
+		for _, c := range n.children {
+			c.namespace = n.namespace
+			if err := b.encode(enc, c, parentNamespace); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: n.name}}
+	namespace := parentNamespace
+	if b.opts.GetIncludeNs() && n.namespace != "" && n.namespace != parentNamespace {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: n.namespace})
+		namespace = n.namespace
+	}
+	if n.operation != "" {
+		if b.opts.GetOperationWithNs() {
+			start.Attr = append(start.Attr,
+				xml.Attr{Name: xml.Name{Local: "xmlns:" + netconfBasePrefix}, Value: NetconfBaseNamespace},
+				xml.Attr{Name: xml.Name{Local: netconfBasePrefix + ":operation"}, Value: n.operation},
+			)
+		} else {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "operation"}, Value: n.operation})
+		}
+	}
+	// declare the prefix of identityref values of the same module
+	if prefix := schemaPrefix(n.schema); b.opts.GetIncludeNs() && n.hasText && prefix != "" && n.namespace != "" &&
+		strings.HasPrefix(n.text, prefix+":") {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: n.namespace})
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	// keys must be the first children of a list entry
+	for _, k := range n.sortedKeyNames() {
+		if err := enc.EncodeElement(n.keys[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+	if n.hasText {
+		if err := enc.EncodeToken(xml.CharData(n.text)); err != nil {
+			return err
+		}
+	}
+	for _, c := range n.children {
+		if _, isKey := n.keys[c.name]; isKey && len(c.children) == 0 {
+			// already rendered as key
+			continue
+		}
+		if err := b.encode(enc, c, namespace); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElement is a generic representation of a parsed XML element.
+type xmlElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Children []xmlElement `xml:",any"`
+	Text     string       `xml:",chardata"`
+}
+
+func (e *xmlElement) operation() string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == "operation" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// XMLToUpdates decodes a NETCONF <config> (or <data>) document into updates and deletes.
+// Elements carrying a delete or remove operation attribute are returned as deletes.
+// The lookup is required to distinguish lists, leaf-lists and leafs and to convert the leaf values.
+func XMLToUpdates(basePath *Path, data []byte, lookup SchemaLookupFunc) ([]*Update, []*Path, error) {
+	if lookup == nil {
+		return nil, nil, fmt.Errorf("schema lookup function must not be nil")
+	}
+	root := &xmlElement{}
+	if err := xml.Unmarshal(data, root); err != nil {
+		return nil, nil, err
+	}
+	if basePath == nil {
+		basePath = &Path{IsRootBased: true}
+	}
+	d := &xmlTreeDecoder{lookup: lookup}
+	if err := d.decodeChildren(basePath, root.Children); err != nil {
+		return nil, nil, err
+	}
+	return d.updates, d.deletes, nil
+}
+
+type xmlTreeDecoder struct {
+	lookup  SchemaLookupFunc
+	updates []*Update
+	deletes []*Path
+}
+
+func (d *xmlTreeDecoder) decodeChildren(parent *Path, children []xmlElement) error {
+	// leaf-list elements are collected into a single update per leaf-list
+	leafLists := map[string]*Update{}
+	for _, c := range children {
+		p := parent.CopyPathAddElem(&PathElem{Name: c.XMLName.Local})
+		schema, err := d.lookup(p)
+		if err != nil {
+			return err
+		}
+		if schema == nil {
+			return fmt.Errorf("unknown element %s", p.ToXPath(false))
+		}
+		text := strings.TrimSpace(c.Text)
+		switch s := schema.GetSchema().(type) {
+		case *SchemaElem_Container:
+			if len(s.Container.GetKeys()) > 0 {
+				keys, err := xmlListKeys(p, s.Container, c.Children)
+				if err != nil {
+					return err
+				}
+				p = parent.CopyPathAddElem(&PathElem{Name: c.XMLName.Local, Key: keys})
+			}
+			if isXMLDeleteOperation(c.operation()) {
+				d.deletes = append(d.deletes, p)
+				continue
+			}
+			if len(c.Children) == 0 && s.Container.GetIsPresence() {
+				d.updates = append(d.updates, &Update{Path: p, Value: &TypedValue{Value: &TypedValue_EmptyVal{}}})
+				continue
+			}
+			if err := d.decodeChildren(p, c.Children); err != nil {
+				return err
+			}
+		case *SchemaElem_Field:
+			if isXMLDeleteOperation(c.operation()) {
+				d.deletes = append(d.deletes, p)
+				continue
+			}
+			tv, err := TVFromString(s.Field.GetType(), text, 0)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.ToXPath(false), err)
+			}
+			d.updates = append(d.updates, &Update{Path: p, Value: tv})
+		case *SchemaElem_Leaflist:
+			if isXMLDeleteOperation(c.operation()) {
+				d.deletes = append(d.deletes, p)
+				continue
+			}
+			tv, err := TVFromString(s.Leaflist.GetType(), text, 0)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.ToXPath(false), err)
+			}
+			u, exists := leafLists[c.XMLName.Local]
+			if !exists {
+				u = &Update{Path: p, Value: &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{}}}}
+				leafLists[c.XMLName.Local] = u
+				d.updates = append(d.updates, u)
+			}
+			u.Value.GetLeaflistVal().Element = append(u.Value.GetLeaflistVal().Element, tv)
+		}
+	}
+	return nil
+}
+
+func isXMLDeleteOperation(op string) bool {
+	return op == "delete" || op == "remove"
+}
+
+// xmlListKeys extracts the values of the list keys from the children of a list entry element.
+func xmlListKeys(p *Path, schema *ContainerSchema, children []xmlElement) (map[string]string, error) {
+	keys := make(map[string]string, len(schema.GetKeys()))
+	for _, k := range schema.GetKeys() {
+		idx := slices.IndexFunc(children, func(c xmlElement) bool { return c.XMLName.Local == k.GetName() })
+		if idx < 0 {
+			return nil, fmt.Errorf("%s: list entry is missing key %q", p.ToXPath(false), k.GetName())
+		}
+		tv, err := TVFromString(k.GetType(), strings.TrimSpace(children[idx].Text), 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", p.ToXPath(false), k.GetName(), err)
+		}
+		keys[k.GetName()] = tv.ToString()
+	}
+	return keys, nil
+}
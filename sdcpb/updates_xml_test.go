@@ -0,0 +1,159 @@
+package sdcpb
+
+import (
+	"slices"
+	"testing"
+)
+
+func testXMLSchemaLookup() SchemaLookupFunc {
+	return testSchemaLookup(map[string]*SchemaElem{
+		"/interfaces": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "interfaces", Namespace: "urn:ietf:params:xml:ns:yang:ietf-interfaces", Prefix: "if",
+		}}},
+		"/interfaces/interface": {Schema: &SchemaElem_Container{Container: &ContainerSchema{
+			Name: "interface", Namespace: "urn:ietf:params:xml:ns:yang:ietf-interfaces", Prefix: "if",
+			Keys: []*LeafSchema{{Name: "name", Type: &SchemaLeafType{Type: "string"}}},
+		}}},
+		"/interfaces/interface/name": {Schema: &SchemaElem_Field{Field: &LeafSchema{
+			Name: "name", Namespace: "urn:ietf:params:xml:ns:yang:ietf-interfaces", Prefix: "if", Type: &SchemaLeafType{Type: "string"},
+		}}},
+		"/interfaces/interface/mtu": {Schema: &SchemaElem_Field{Field: &LeafSchema{
+			Name: "mtu", Namespace: "urn:ietf:params:xml:ns:yang:ietf-ip", Prefix: "ip", Type: &SchemaLeafType{Type: "uint16"},
+		}}},
+		"/interfaces/interface/description": {Schema: &SchemaElem_Field{Field: &LeafSchema{
+			Name: "description", Namespace: "urn:ietf:params:xml:ns:yang:ietf-interfaces", Prefix: "if", Type: &SchemaLeafType{Type: "string"},
+		}}},
+		"/interfaces/interface/tag": {Schema: &SchemaElem_Leaflist{Leaflist: &LeafListSchema{
+			Name: "tag", Namespace: "urn:ietf:params:xml:ns:yang:ietf-interfaces", Prefix: "if", Type: &SchemaLeafType{Type: "string"},
+		}}},
+	})
+}
+
+func TestUpdatesToXML(t *testing.T) {
+	updates := []*Update{
+		{
+			Path:  mustParsePath(t, "/interfaces/interface[name=eth0]/description"),
+			Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "a < b"}},
+		},
+		{
+			Path:  mustParsePath(t, "/interfaces/interface[name=eth0]/mtu"),
+			Value: &TypedValue{Value: &TypedValue_UintVal{UintVal: 1500}},
+		},
+		{
+			Path: mustParsePath(t, "/interfaces/interface[name=eth0]/tag"),
+			Value: &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+				{Value: &TypedValue_StringVal{StringVal: "x"}},
+				{Value: &TypedValue_StringVal{StringVal: "y"}},
+			}}}},
+		},
+	}
+	deletes := []*Path{
+		mustParsePath(t, "/interfaces/interface[name=eth1]"),
+	}
+
+	tests := []struct {
+		name   string
+		opts   *NetconfOptions
+		lookup SchemaLookupFunc
+		want   string
+	}{
+		{
+			name: "defaults without schema",
+			want: `<config><interfaces><interface operation="delete"><name>eth1</name></interface>` +
+				`<interface><name>eth0</name><description>a &lt; b</description><mtu>1500</mtu><tag>x</tag><tag>y</tag></interface>` +
+				`</interfaces></config>`,
+		},
+		{
+			name:   "include namespaces, namespaced remove operation",
+			opts:   &NetconfOptions{IncludeNs: true, OperationWithNs: true, UseOperationRemove: true},
+			lookup: testXMLSchemaLookup(),
+			want: `<config><interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">` +
+				`<interface xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="remove"><name>eth1</name></interface>` +
+				`<interface><name>eth0</name><description>a &lt; b</description><mtu xmlns="urn:ietf:params:xml:ns:yang:ietf-ip">1500</mtu><tag>x</tag><tag>y</tag></interface>` +
+				`</interfaces></config>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpdatesToXML(updates, deletes, tt.opts, tt.lookup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("UpdatesToXML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXMLToUpdates(t *testing.T) {
+	doc := `<config>
+	<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">
+		<interface xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="delete"><name>eth1</name></interface>
+		<interface>
+			<name>eth0</name>
+			<mtu xmlns="urn:ietf:params:xml:ns:yang:ietf-ip">1500</mtu>
+			<tag>x</tag>
+			<description operation="remove"/>
+			<tag>y</tag>
+		</interface>
+	</interfaces>
+</config>`
+	updates, deletes, err := XMLToUpdates(nil, []byte(doc), testXMLSchemaLookup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotUpdates := []string{}
+	for _, u := range updates {
+		gotUpdates = append(gotUpdates, u.GetPath().ToXPath(false)+": "+u.GetValue().ToString())
+	}
+	slices.Sort(gotUpdates)
+	wantUpdates := []string{
+		"/interfaces/interface[name=eth0]/mtu: 1500",
+		"/interfaces/interface[name=eth0]/name: eth0",
+		"/interfaces/interface[name=eth0]/tag: x,y",
+	}
+	if !slices.Equal(gotUpdates, wantUpdates) {
+		t.Errorf("updates = %v, want %v", gotUpdates, wantUpdates)
+	}
+	gotDeletes := Paths(deletes).ToXPathSlice()
+	slices.Sort(gotDeletes)
+	wantDeletes := []string{
+		"/interfaces/interface[name=eth0]/description",
+		"/interfaces/interface[name=eth1]",
+	}
+	if !slices.Equal(gotDeletes, wantDeletes) {
+		t.Errorf("deletes = %v, want %v", gotDeletes, wantDeletes)
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	lookup := testXMLSchemaLookup()
+	updates := []*Update{
+		{
+			Path:  mustParsePath(t, "/interfaces/interface[name=eth0]/name"),
+			Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "eth0"}},
+		},
+		{
+			Path:  mustParsePath(t, "/interfaces/interface[name=eth0]/mtu"),
+			Value: &TypedValue{Value: &TypedValue_UintVal{UintVal: 9000}},
+		},
+	}
+	// keys are rendered first, the updates are therefore expected in that order
+	doc, err := UpdatesToXML(updates, nil, &NetconfOptions{IncludeNs: true}, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := XMLToUpdates(nil, doc, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(updates) {
+		t.Fatalf("got %d updates, want %d", len(got), len(updates))
+	}
+	for i := range got {
+		if !got[i].GetPath().PathsEqual(updates[i].GetPath()) || !got[i].GetValue().Equal(updates[i].GetValue()) {
+			t.Errorf("update %d = %v, want %v", i, got[i], updates[i])
+		}
+	}
+}
@@ -0,0 +1,561 @@
+package sdcpb
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// XPathExpr is a compiled XPath 1.0 expression, as used by YANG must and when statements.
+// It is created via CompileXPath and evaluated via Evaluate.
+type XPathExpr struct {
+	src  string
+	root xpathExpr
+	// patterns caches the compiled patterns of re-match()
+	patterns sync.Map
+}
+
+// CompileXPath parses the XPath 1.0 expression. Variables and the attribute, namespace and text nodes are not supported.
+func CompileXPath(s string) (*XPathExpr, error) {
+	tokens, err := xpathLex(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpath %q: %w", s, err)
+	}
+	p := &xpathParser{tokens: tokens}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpath %q: %w", s, err)
+	}
+	if t := p.peek(); t.kind != xtEOF {
+		return nil, fmt.Errorf("invalid xpath %q: unexpected %q", s, t.text)
+	}
+	return &XPathExpr{src: s, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *XPathExpr) String() string {
+	return e.src
+}
+
+type xpathTokenKind int
+
+const (
+	xtEOF xpathTokenKind = iota
+	xtNumber
+	xtLiteral
+	// xtName is a name test, a NCName, a QName, "*" or "prefix:*"
+	xtName
+	// xtOperator holds and, or, div, mod, *, /, //, |, +, -, =, !=, <, <=, >, >=
+	xtOperator
+	// xtFunction is a function name, it is followed by '('
+	xtFunction
+	// xtNodeType is one of node, text, comment or processing-instruction, it is followed by '('
+	xtNodeType
+	// xtAxis is an axis name, the following "::" is consumed
+	xtAxis
+	// xtPunct holds ( ) [ ] . .. @ ,
+	xtPunct
+	xtVariable
+)
+
+type xpathToken struct {
+	kind xpathTokenKind
+	text string
+	num  float64
+}
+
+func (t xpathToken) is(kind xpathTokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+var xpathNodeTypes = map[string]bool{"node": true, "text": true, "comment": true, "processing-instruction": true}
+
+// xpathLex splits the expression into tokens, applying the disambiguation rules of XPath 1.0 section 3.7.
+func xpathLex(s string) ([]xpathToken, error) {
+	tokens := []xpathToken{}
+	// operatorContext reports whether a '*' or a name is to be read as an operator
+	operatorContext := func() bool {
+		if len(tokens) == 0 {
+			return false
+		}
+		prev := tokens[len(tokens)-1]
+		switch prev.kind {
+		case xtOperator, xtAxis:
+			return false
+		case xtPunct:
+			return prev.text == ")" || prev.text == "]" || prev.text == "." || prev.text == ".."
+		}
+		return true
+	}
+
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated literal at offset %d", i)
+			}
+			tokens = append(tokens, xpathToken{kind: xtLiteral, text: s[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			start := i
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+				i++
+			}
+			num, err := strconv.ParseFloat(s[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[start:i])
+			}
+			tokens = append(tokens, xpathToken{kind: xtNumber, text: s[start:i], num: num})
+		case c == '.':
+			if strings.HasPrefix(s[i:], "..") {
+				tokens = append(tokens, xpathToken{kind: xtPunct, text: ".."})
+				i += 2
+			} else {
+				tokens = append(tokens, xpathToken{kind: xtPunct, text: "."})
+				i++
+			}
+		case c == '(' || c == ')' || c == '[' || c == ']' || c == '@' || c == ',':
+			tokens = append(tokens, xpathToken{kind: xtPunct, text: string(c)})
+			i++
+		case c == '/':
+			if strings.HasPrefix(s[i:], "//") {
+				tokens = append(tokens, xpathToken{kind: xtOperator, text: "//"})
+				i += 2
+			} else {
+				tokens = append(tokens, xpathToken{kind: xtOperator, text: "/"})
+				i++
+			}
+		case c == '|' || c == '+' || c == '-' || c == '=':
+			tokens = append(tokens, xpathToken{kind: xtOperator, text: string(c)})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(s) && s[i+1] == '=' {
+				op += "="
+			} else if c == '!' {
+				return nil, fmt.Errorf("unexpected '!' at offset %d", i)
+			}
+			tokens = append(tokens, xpathToken{kind: xtOperator, text: op})
+			i += len(op)
+		case c == '*':
+			if operatorContext() {
+				tokens = append(tokens, xpathToken{kind: xtOperator, text: "*"})
+			} else {
+				tokens = append(tokens, xpathToken{kind: xtName, text: "*"})
+			}
+			i++
+		case c == '$':
+			name, n := xpathScanNCName(s[i+1:])
+			if n == 0 {
+				return nil, fmt.Errorf("invalid variable reference at offset %d", i)
+			}
+			tokens = append(tokens, xpathToken{kind: xtVariable, text: name})
+			i += n + 1
+		default:
+			name, n := xpathScanNCName(s[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			i += n
+			if operatorContext() {
+				switch name {
+				case "and", "or", "div", "mod":
+					tokens = append(tokens, xpathToken{kind: xtOperator, text: name})
+					continue
+				}
+				return nil, fmt.Errorf("unexpected name %q at offset %d", name, i-n)
+			}
+			// QName or prefix:*
+			if i+1 < len(s) && s[i] == ':' && s[i+1] != ':' {
+				if s[i+1] == '*' {
+					name += ":*"
+					i += 2
+				} else if local, m := xpathScanNCName(s[i+1:]); m > 0 {
+					name += ":" + local
+					i += m + 1
+				}
+			}
+			j := i
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\n' || s[j] == '\r') {
+				j++
+			}
+			switch {
+			case strings.HasPrefix(s[j:], "::"):
+				tokens = append(tokens, xpathToken{kind: xtAxis, text: name})
+				i = j + 2
+			case j < len(s) && s[j] == '(' && xpathNodeTypes[name]:
+				tokens = append(tokens, xpathToken{kind: xtNodeType, text: name})
+			case j < len(s) && s[j] == '(':
+				tokens = append(tokens, xpathToken{kind: xtFunction, text: name})
+			default:
+				tokens = append(tokens, xpathToken{kind: xtName, text: name})
+			}
+		}
+	}
+	return append(tokens, xpathToken{kind: xtEOF}), nil
+}
+
+// xpathScanNCName returns the NCName at the start of s and its length in bytes.
+func xpathScanNCName(s string) (string, int) {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		valid := r == '_' || unicode.IsLetter(r)
+		if n > 0 {
+			valid = valid || r == '-' || r == '.' || unicode.IsDigit(r)
+		}
+		if !valid {
+			break
+		}
+		n += size
+	}
+	return s[:n], n
+}
+
+// xpathExpr is a node of the abstract syntax tree of an XPath expression.
+type xpathExpr interface {
+	eval(c *xpathContext) (any, error)
+}
+
+type xpathBinaryExpr struct {
+	op          string
+	left, right xpathExpr
+}
+
+type xpathNegExpr struct {
+	expr xpathExpr
+}
+
+type xpathLiteral struct {
+	value string
+}
+
+type xpathNumber struct {
+	value float64
+}
+
+type xpathFunctionCall struct {
+	name string
+	args []xpathExpr
+}
+
+// xpathPathExpr is a location path, optionally starting from a filter expression instead of the context node.
+type xpathPathExpr struct {
+	filter   xpathExpr
+	absolute bool
+	steps    []*xpathStep
+}
+
+type xpathFilterExpr struct {
+	primary    xpathExpr
+	predicates []xpathExpr
+}
+
+type xpathStep struct {
+	axis string
+	// test is the name test, "*" for any name, "node()" for any node
+	test       string
+	predicates []xpathExpr
+}
+
+var xpathAxes = map[string]bool{
+	"ancestor": true, "ancestor-or-self": true, "attribute": true, "child": true, "descendant": true,
+	"descendant-or-self": true, "following": true, "following-sibling": true, "namespace": true,
+	"parent": true, "preceding": true, "preceding-sibling": true, "self": true,
+}
+
+type xpathParser struct {
+	tokens []xpathToken
+	pos    int
+}
+
+func (p *xpathParser) peek() xpathToken {
+	return p.tokens[p.pos]
+}
+
+func (p *xpathParser) next() xpathToken {
+	t := p.tokens[p.pos]
+	if t.kind != xtEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *xpathParser) expect(kind xpathTokenKind, text string) error {
+	if t := p.next(); !t.is(kind, text) {
+		if t.kind == xtEOF {
+			return fmt.Errorf("expected %q, got end of expression", text)
+		}
+		return fmt.Errorf("expected %q, got %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *xpathParser) parseExpr() (xpathExpr, error) {
+	return p.parseBinary(0)
+}
+
+// xpathPrecedence holds the binary operators by increasing precedence, the union is handled in parseUnary.
+var xpathPrecedence = [][]string{
+	{"or"},
+	{"and"},
+	{"=", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "div", "mod"},
+}
+
+func (p *xpathParser) parseBinary(level int) (xpathExpr, error) {
+	if level == len(xpathPrecedence) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != xtOperator || !slices.Contains(xpathPrecedence[level], t.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &xpathBinaryExpr{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *xpathParser) parseUnary() (xpathExpr, error) {
+	if p.peek().is(xtOperator, "-") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &xpathNegExpr{expr: expr}, nil
+	}
+	left, err := p.parsePathExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is(xtOperator, "|") {
+		p.next()
+		right, err := p.parsePathExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = &xpathBinaryExpr{op: "|", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *xpathParser) parsePathExpr() (xpathExpr, error) {
+	t := p.peek()
+	switch {
+	case t.is(xtOperator, "/"):
+		p.next()
+		path := &xpathPathExpr{absolute: true}
+		if !p.startsStep() {
+			return path, nil
+		}
+		return path, p.parseRelativePath(path)
+	case t.is(xtOperator, "//"):
+		p.next()
+		path := &xpathPathExpr{absolute: true, steps: []*xpathStep{{axis: "descendant-or-self", test: "node()"}}}
+		return path, p.parseRelativePath(path)
+	case t.kind == xtLiteral, t.kind == xtNumber, t.kind == xtFunction, t.kind == xtVariable, t.is(xtPunct, "("):
+		filter, err := p.parseFilterExpr()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case p.peek().is(xtOperator, "/"):
+			p.next()
+		case p.peek().is(xtOperator, "//"):
+			p.next()
+			path := &xpathPathExpr{filter: filter, steps: []*xpathStep{{axis: "descendant-or-self", test: "node()"}}}
+			return path, p.parseRelativePath(path)
+		default:
+			return filter, nil
+		}
+		path := &xpathPathExpr{filter: filter}
+		return path, p.parseRelativePath(path)
+	}
+	path := &xpathPathExpr{}
+	return path, p.parseRelativePath(path)
+}
+
+// startsStep reports whether the next token can start a location step.
+func (p *xpathParser) startsStep() bool {
+	t := p.peek()
+	switch t.kind {
+	case xtName, xtAxis, xtNodeType:
+		return true
+	case xtPunct:
+		return t.text == "." || t.text == ".." || t.text == "@"
+	}
+	return false
+}
+
+func (p *xpathParser) parseRelativePath(path *xpathPathExpr) error {
+	for {
+		step, err := p.parseStep()
+		if err != nil {
+			return err
+		}
+		path.steps = append(path.steps, step)
+		switch {
+		case p.peek().is(xtOperator, "/"):
+			p.next()
+		case p.peek().is(xtOperator, "//"):
+			p.next()
+			path.steps = append(path.steps, &xpathStep{axis: "descendant-or-self", test: "node()"})
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *xpathParser) parseStep() (*xpathStep, error) {
+	t := p.next()
+	switch {
+	case t.is(xtPunct, "."):
+		return &xpathStep{axis: "self", test: "node()"}, nil
+	case t.is(xtPunct, ".."):
+		return &xpathStep{axis: "parent", test: "node()"}, nil
+	}
+	step := &xpathStep{axis: "child"}
+	switch {
+	case t.is(xtPunct, "@"):
+		step.axis = "attribute"
+		t = p.next()
+	case t.kind == xtAxis:
+		if !xpathAxes[t.text] {
+			return nil, fmt.Errorf("unknown axis %q", t.text)
+		}
+		step.axis = t.text
+		t = p.next()
+	}
+	switch t.kind {
+	case xtName:
+		step.test = t.text
+	case xtNodeType:
+		if t.text != "node" {
+			return nil, fmt.Errorf("node type test %s() is not supported", t.text)
+		}
+		if err := p.expect(xtPunct, "("); err != nil {
+			return nil, err
+		}
+		if err := p.expect(xtPunct, ")"); err != nil {
+			return nil, err
+		}
+		step.test = "node()"
+	case xtEOF:
+		return nil, fmt.Errorf("expected location step, got end of expression")
+	default:
+		return nil, fmt.Errorf("expected location step, got %q", t.text)
+	}
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	step.predicates = predicates
+	return step, nil
+}
+
+func (p *xpathParser) parsePredicates() ([]xpathExpr, error) {
+	var predicates []xpathExpr
+	for p.peek().is(xtPunct, "[") {
+		p.next()
+		pred, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(xtPunct, "]"); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, pred)
+	}
+	return predicates, nil
+}
+
+func (p *xpathParser) parseFilterExpr() (xpathExpr, error) {
+	primary, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	if len(predicates) == 0 {
+		return primary, nil
+	}
+	return &xpathFilterExpr{primary: primary, predicates: predicates}, nil
+}
+
+func (p *xpathParser) parsePrimary() (xpathExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case xtLiteral:
+		return &xpathLiteral{value: t.text}, nil
+	case xtNumber:
+		return &xpathNumber{value: t.num}, nil
+	case xtVariable:
+		return nil, fmt.Errorf("variable $%s is not supported", t.text)
+	case xtFunction:
+		if err := p.expect(xtPunct, "("); err != nil {
+			return nil, err
+		}
+		return p.parseFunctionArgs(t.text)
+	}
+	if t.is(xtPunct, "(") {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(xtPunct, ")")
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (p *xpathParser) parseFunctionArgs(name string) (xpathExpr, error) {
+	f, known := xpathFunctions[name]
+	if !known {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	call := &xpathFunctionCall{name: name}
+	if p.peek().is(xtPunct, ")") {
+		p.next()
+	} else {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if !p.peek().is(xtPunct, ",") {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(xtPunct, ")"); err != nil {
+			return nil, err
+		}
+	}
+	if len(call.args) < f.minArgs || (f.maxArgs != xpathUnlimitedArgs && len(call.args) > f.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments for %s()", name)
+	}
+	return call, nil
+}
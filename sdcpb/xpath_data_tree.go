@@ -0,0 +1,217 @@
+package sdcpb
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// DataTree is a data tree XPath expressions can be evaluated on.
+type DataTree interface {
+	// DataRoot returns the root node of the data tree.
+	DataRoot() XPathNode
+}
+
+// Updates is a list of updates that can be used as DataTree.
+type Updates []*Update
+
+// DataRoot builds a data tree out of the updates, see NewUpdatesDataTree.
+func (u Updates) DataRoot() XPathNode {
+	return NewUpdatesDataTree(u)
+}
+
+// DataRoot builds a data tree out of the updates of the notification, the deletes are not considered.
+func (x *Notification) DataRoot() XPathNode {
+	return NewUpdatesDataTree(x.GetUpdate())
+}
+
+// dataTreeNode is the XPathNode implementation used for update based data trees.
+type dataTreeNode struct {
+	name     string
+	parent   *dataTreeNode
+	children []XPathNode
+	// index holds the non leaf-list children by their name and keys, see pathElemKeyString
+	index    map[string]*dataTreeNode
+	value    *TypedValue
+	path     *Path
+	leafList bool
+}
+
+func (n *dataTreeNode) Name() string {
+	return n.name
+}
+
+func (n *dataTreeNode) Parent() XPathNode {
+	// avoid returning a typed nil
+	if n.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func (n *dataTreeNode) Children() []XPathNode {
+	return n.children
+}
+
+func (n *dataTreeNode) Value() *TypedValue {
+	return n.value
+}
+
+func (n *dataTreeNode) Path() *Path {
+	return n.path
+}
+
+// child returns the child for the path element, creating it if it does not exist yet.
+// The keys of list entries are added as key leaf children.
+func (n *dataTreeNode) child(pe *PathElem) *dataTreeNode {
+	idx := pe.GetName() + "\x00" + pathElemKeyString(pe)
+	if c, exists := n.index[idx]; exists {
+		return c
+	}
+	c := &dataTreeNode{
+		name:   pe.GetName(),
+		parent: n,
+		index:  map[string]*dataTreeNode{},
+		path:   n.path.CopyPathAddElem(pe),
+	}
+	n.index[idx] = c
+	n.children = append(n.children, c)
+	for _, k := range slices.Sorted(maps.Keys(pe.GetKey())) {
+		c.child(&PathElem{Name: k}).value = &TypedValue{Value: &TypedValue_StringVal{StringVal: pe.GetKey()[k]}}
+	}
+	return c
+}
+
+// NewUpdatesDataTree builds a data tree out of the updates and returns its root. Module prefixes are removed
+// from the element names. Leaf-list values result in one node per entry, later updates replace earlier ones.
+func NewUpdatesDataTree(updates []*Update) XPathNode {
+	root := &dataTreeNode{
+		index: map[string]*dataTreeNode{},
+		path:  &Path{IsRootBased: true},
+	}
+	for _, u := range updates {
+		elems := u.GetPath().DeepCopy().StripPathElemPrefixPath().GetElem()
+		if len(elems) == 0 {
+			continue
+		}
+		n := root
+		for _, pe := range elems[:len(elems)-1] {
+			n = n.child(pe)
+		}
+		last := elems[len(elems)-1]
+		ll := u.GetValue().GetLeaflistVal()
+		if ll == nil {
+			n.child(last).value = u.GetValue()
+			continue
+		}
+		n.children = slices.DeleteFunc(n.children, func(c XPathNode) bool {
+			return c.(*dataTreeNode).leafList && c.Name() == last.GetName()
+		})
+		for _, e := range ll.GetElement() {
+			n.children = append(n.children, &dataTreeNode{
+				name:     last.GetName(),
+				parent:   n,
+				value:    e,
+				path:     n.path.CopyPathAddElem(last),
+				leafList: true,
+			})
+		}
+	}
+	return root
+}
+
+// MustStatementError is returned if a must statement is not satisfied.
+type MustStatementError struct {
+	// Path is the path of the context node of the must statement.
+	Path      *Path
+	Statement string
+	// Message is the error message supplied by the schema.
+	Message string
+}
+
+func (e *MustStatementError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s: %s", e.Path.ToXPath(false), e.Message)
+	}
+	return fmt.Sprintf("%s: must statement %q not satisfied", e.Path.ToXPath(false), e.Statement)
+}
+
+// EvaluateMustStatements evaluates the must statements with node as context node and returns a
+// MustStatementError for the first statement that is not satisfied.
+func EvaluateMustStatements(statements []*MustStatement, node XPathNode, env *XPathEnv) error {
+	for _, ms := range statements {
+		expr, err := CompileXPath(ms.GetStatement())
+		if err != nil {
+			return err
+		}
+		if err := evaluateMustStatement(ms, expr, node, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evaluateMustStatement(ms *MustStatement, expr *XPathExpr, node XPathNode, env *XPathEnv) error {
+	ok, err := expr.EvaluateBool(node, env)
+	if err != nil {
+		return fmt.Errorf("%s: evaluating must statement %q: %w", node.Path().ToXPath(false), ms.GetStatement(), err)
+	}
+	if !ok {
+		return &MustStatementError{Path: node.Path(), Statement: ms.GetStatement(), Message: ms.GetError()}
+	}
+	return nil
+}
+
+// ValidateMustStatements evaluates the must statements of all nodes of the data tree, as defined by the schema
+// of env, which is required. All violations are returned, joined into a single error.
+func ValidateMustStatements(tree DataTree, env *XPathEnv) error {
+	if env == nil || env.Schema == nil {
+		return errors.New("validating must statements requires a schema")
+	}
+	compiled := map[string]*XPathExpr{}
+	var errs []error
+	var walk func(n XPathNode)
+	walk = func(n XPathNode) {
+		for _, c := range n.Children() {
+			if err := validateNodeMustStatements(c, env, compiled); err != nil {
+				errs = append(errs, err)
+			}
+			walk(c)
+		}
+	}
+	walk(tree.DataRoot())
+	return errors.Join(errs...)
+}
+
+func validateNodeMustStatements(n XPathNode, env *XPathEnv, compiled map[string]*XPathExpr) error {
+	schema, err := env.Schema(n.Path())
+	if err != nil {
+		return err
+	}
+	var statements []*MustStatement
+	switch {
+	case schema.GetContainer() != nil:
+		statements = schema.GetContainer().GetMustStatements()
+	case schema.GetField() != nil:
+		statements = schema.GetField().GetMustStatements()
+	case schema.GetLeaflist() != nil:
+		statements = schema.GetLeaflist().GetMustStatements()
+	}
+	var errs []error
+	for _, ms := range statements {
+		expr, exists := compiled[ms.GetStatement()]
+		if !exists {
+			expr, err = CompileXPath(ms.GetStatement())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", n.Path().ToXPath(false), err))
+				continue
+			}
+			compiled[ms.GetStatement()] = expr
+		}
+		if err := evaluateMustStatement(ms, expr, n, env); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,867 @@
+package sdcpb
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// XPathNode is a node of the data tree XPath expressions are evaluated on. The root node has no parent and no name.
+// Nodes are compared by identity, implementations must therefore be comparable, e.g. pointers.
+type XPathNode interface {
+	// Name returns the name of the node, module prefixes are ignored.
+	Name() string
+	// Parent returns the parent node, or nil for the root node.
+	Parent() XPathNode
+	// Children returns the child nodes in document order. Each entry of a leaf-list is a separate node.
+	Children() []XPathNode
+	// Value returns the value of leaf and leaf-list entry nodes, nil for all other nodes.
+	Value() *TypedValue
+	// Path returns the path of the node.
+	Path() *Path
+}
+
+// XPathEnv provides the optional information required by the YANG extension functions.
+type XPathEnv struct {
+	// Schema is used by deref() and enum-value().
+	Schema SchemaLookupFunc
+	// IdentityBases returns the names of the direct base identities of the identity, it is used by derived-from()
+	// and derived-from-or-self(). Identities are compared by name, module prefixes are ignored.
+	IdentityBases func(identity string) []string
+}
+
+// Evaluate evaluates the expression with node as context and current() node. The result is either
+// a node-set ([]XPathNode in document order), a string, a number (float64) or a bool. The env may be nil.
+func (e *XPathExpr) Evaluate(node XPathNode, env *XPathEnv) (any, error) {
+	if env == nil {
+		env = &XPathEnv{}
+	}
+	ev := &xpathEvaluation{expr: e, current: node, env: env}
+	return e.root.eval(&xpathContext{node: node, pos: 1, size: 1, ev: ev})
+}
+
+// EvaluateBool evaluates the expression and converts the result to a boolean, as done for must statements.
+func (e *XPathExpr) EvaluateBool(node XPathNode, env *XPathEnv) (bool, error) {
+	v, err := e.Evaluate(node, env)
+	if err != nil {
+		return false, err
+	}
+	return xpathToBool(v), nil
+}
+
+// xpathEvaluation holds the state shared by all contexts of a single evaluation.
+type xpathEvaluation struct {
+	expr    *XPathExpr
+	current XPathNode
+	env     *XPathEnv
+	// position caches the document position of the nodes sorted so far, see documentPosition
+	position map[XPathNode][]int
+	// childIndex caches the index of each child of the parents visited by documentPosition
+	childIndex map[XPathNode]map[XPathNode]int
+}
+
+type xpathContext struct {
+	node      XPathNode
+	pos, size int
+	ev        *xpathEvaluation
+}
+
+func xpathRoot(n XPathNode) XPathNode {
+	for p := n.Parent(); p != nil; p = n.Parent() {
+		n = p
+	}
+	return n
+}
+
+// documentPosition returns the indexes of the ancestors of the node and the node itself within their parents,
+// starting below the root. Comparing positions lexicographically yields the document order. Only the ancestors
+// of the node and their children are visited, not the whole tree.
+func (ev *xpathEvaluation) documentPosition(n XPathNode) []int {
+	if pos, exists := ev.position[n]; exists {
+		return pos
+	}
+	if ev.position == nil {
+		ev.position = map[XPathNode][]int{}
+		ev.childIndex = map[XPathNode]map[XPathNode]int{}
+	}
+	p := n.Parent()
+	if p == nil {
+		ev.position[n] = []int{}
+		return ev.position[n]
+	}
+	idx, exists := ev.childIndex[p]
+	if !exists {
+		idx = map[XPathNode]int{}
+		for i, c := range p.Children() {
+			idx[c] = i
+		}
+		ev.childIndex[p] = idx
+	}
+	parentPos := ev.documentPosition(p)
+	pos := make([]int, len(parentPos), len(parentPos)+1)
+	copy(pos, parentPos)
+	pos = append(pos, idx[n])
+	ev.position[n] = pos
+	return pos
+}
+
+// sortNodes sorts the nodes in document order and removes duplicates.
+func (ev *xpathEvaluation) sortNodes(nodes []XPathNode) []XPathNode {
+	if len(nodes) < 2 {
+		return nodes
+	}
+	slices.SortFunc(nodes, func(a, b XPathNode) int {
+		return slices.Compare(ev.documentPosition(a), ev.documentPosition(b))
+	})
+	return slices.Compact(nodes)
+}
+
+// xpathAppendDescendants appends the descendants of n in document order.
+func xpathAppendDescendants(result []XPathNode, n XPathNode) []XPathNode {
+	for _, c := range n.Children() {
+		result = append(result, c)
+		result = xpathAppendDescendants(result, c)
+	}
+	return result
+}
+
+func (e *xpathLiteral) eval(*xpathContext) (any, error) {
+	return e.value, nil
+}
+
+func (e *xpathNumber) eval(*xpathContext) (any, error) {
+	return e.value, nil
+}
+
+func (e *xpathNegExpr) eval(c *xpathContext) (any, error) {
+	v, err := e.expr.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	return -xpathToNumber(v), nil
+}
+
+func (e *xpathBinaryExpr) eval(c *xpathContext) (any, error) {
+	left, err := e.left.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	// short circuit evaluation of the boolean operators
+	switch e.op {
+	case "or":
+		if xpathToBool(left) {
+			return true, nil
+		}
+	case "and":
+		if !xpathToBool(left) {
+			return false, nil
+		}
+	}
+	right, err := e.right.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "or", "and":
+		return xpathToBool(right), nil
+	case "=", "!=", "<", "<=", ">", ">=":
+		return xpathCompare(e.op, left, right), nil
+	case "+":
+		return xpathToNumber(left) + xpathToNumber(right), nil
+	case "-":
+		return xpathToNumber(left) - xpathToNumber(right), nil
+	case "*":
+		return xpathToNumber(left) * xpathToNumber(right), nil
+	case "div":
+		return xpathToNumber(left) / xpathToNumber(right), nil
+	case "mod":
+		return math.Mod(xpathToNumber(left), xpathToNumber(right)), nil
+	case "|":
+		ln, lok := left.([]XPathNode)
+		rn, rok := right.([]XPathNode)
+		if !lok || !rok {
+			return nil, fmt.Errorf("union requires node-sets")
+		}
+		return c.ev.sortNodes(append(slices.Clone(ln), rn...)), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", e.op)
+}
+
+func (e *xpathFilterExpr) eval(c *xpathContext) (any, error) {
+	v, err := e.primary.eval(c)
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := v.([]XPathNode)
+	if !ok {
+		return nil, fmt.Errorf("predicates require a node-set")
+	}
+	return xpathApplyPredicates(c.ev, nodes, e.predicates)
+}
+
+func (e *xpathPathExpr) eval(c *xpathContext) (any, error) {
+	var nodes []XPathNode
+	switch {
+	case e.filter != nil:
+		v, err := e.filter.eval(c)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if nodes, ok = v.([]XPathNode); !ok {
+			return nil, fmt.Errorf("location path requires a node-set")
+		}
+	case e.absolute:
+		nodes = []XPathNode{xpathRoot(c.node)}
+	default:
+		nodes = []XPathNode{c.node}
+	}
+	for _, s := range e.steps {
+		next := []XPathNode{}
+		for _, n := range nodes {
+			selected, err := s.apply(c.ev, n)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, selected...)
+		}
+		nodes = c.ev.sortNodes(next)
+	}
+	return nodes, nil
+}
+
+// apply returns the nodes selected by the step for the context node n.
+func (s *xpathStep) apply(ev *xpathEvaluation, n XPathNode) ([]XPathNode, error) {
+	candidates := []XPathNode{}
+	for _, a := range ev.axis(s.axis, n) {
+		if s.matches(a) {
+			candidates = append(candidates, a)
+		}
+	}
+	return xpathApplyPredicates(ev, candidates, s.predicates)
+}
+
+func (s *xpathStep) matches(n XPathNode) bool {
+	switch {
+	case s.test == "node()":
+		return true
+	case n.Parent() == nil:
+		// the root node has no name
+		return false
+	case s.test == "*" || strings.HasSuffix(s.test, ":*"):
+		return true
+	}
+	_, name := splitModuleName(s.test)
+	_, nodeName := splitModuleName(n.Name())
+	return name == nodeName
+}
+
+// axis returns the nodes of the axis in axis order, i.e. reverse document order for the reverse axes.
+func (ev *xpathEvaluation) axis(axis string, n XPathNode) []XPathNode {
+	switch axis {
+	case "self":
+		return []XPathNode{n}
+	case "child":
+		return n.Children()
+	case "parent":
+		if p := n.Parent(); p != nil {
+			return []XPathNode{p}
+		}
+		return nil
+	case "ancestor", "ancestor-or-self":
+		result := []XPathNode{}
+		if axis == "ancestor-or-self" {
+			result = append(result, n)
+		}
+		for p := n.Parent(); p != nil; p = p.Parent() {
+			result = append(result, p)
+		}
+		return result
+	case "descendant", "descendant-or-self":
+		result := []XPathNode{}
+		if axis == "descendant-or-self" {
+			result = append(result, n)
+		}
+		return xpathAppendDescendants(result, n)
+	case "following-sibling", "preceding-sibling":
+		p := n.Parent()
+		if p == nil {
+			return nil
+		}
+		siblings := p.Children()
+		idx := slices.Index(siblings, n)
+		if axis == "following-sibling" {
+			return siblings[idx+1:]
+		}
+		result := slices.Clone(siblings[:idx])
+		slices.Reverse(result)
+		return result
+	case "following", "preceding":
+		// the siblings after (or before) the node and each of its ancestors, with their descendants
+		result := []XPathNode{}
+		for a := n; a.Parent() != nil; a = a.Parent() {
+			siblings := a.Parent().Children()
+			idx := slices.Index(siblings, a)
+			if axis == "following" {
+				for _, s := range siblings[idx+1:] {
+					result = append(result, s)
+					result = xpathAppendDescendants(result, s)
+				}
+				continue
+			}
+			for i := idx - 1; i >= 0; i-- {
+				subtree := xpathAppendDescendants([]XPathNode{siblings[i]}, siblings[i])
+				slices.Reverse(subtree)
+				result = append(result, subtree...)
+			}
+		}
+		return result
+	}
+	// attribute and namespace nodes do not exist in the data tree
+	return nil
+}
+
+// xpathApplyPredicates filters the nodes, given in axis order, by the predicates.
+func xpathApplyPredicates(ev *xpathEvaluation, nodes []XPathNode, predicates []xpathExpr) ([]XPathNode, error) {
+	for _, pred := range predicates {
+		filtered := []XPathNode{}
+		for i, n := range nodes {
+			v, err := pred.eval(&xpathContext{node: n, pos: i + 1, size: len(nodes), ev: ev})
+			if err != nil {
+				return nil, err
+			}
+			if num, ok := v.(float64); ok {
+				if num == float64(i+1) {
+					filtered = append(filtered, n)
+				}
+				continue
+			}
+			if xpathToBool(v) {
+				filtered = append(filtered, n)
+			}
+		}
+		nodes = filtered
+	}
+	return nodes, nil
+}
+
+// xpathCompare implements the comparison of XPath 1.0 section 3.4.
+func xpathCompare(op string, left, right any) bool {
+	ln, lIsSet := left.([]XPathNode)
+	rn, rIsSet := right.([]XPathNode)
+	switch {
+	case lIsSet && rIsSet:
+		for _, a := range ln {
+			for _, b := range rn {
+				if xpathCompareAtoms(op, xpathStringValue(a), xpathStringValue(b)) {
+					return true
+				}
+			}
+		}
+		return false
+	case lIsSet:
+		return xpathCompareNodeSet(op, ln, right)
+	case rIsSet:
+		flipped := map[string]string{"<": ">", "<=": ">=", ">": "<", ">=": "<="}
+		if f, exists := flipped[op]; exists {
+			op = f
+		}
+		return xpathCompareNodeSet(op, rn, left)
+	}
+	return xpathCompareAtoms(op, left, right)
+}
+
+func xpathCompareNodeSet(op string, nodes []XPathNode, other any) bool {
+	if b, ok := other.(bool); ok {
+		return xpathCompareAtoms(op, len(nodes) > 0, b)
+	}
+	for _, n := range nodes {
+		if xpathCompareAtoms(op, xpathStringValue(n), other) {
+			return true
+		}
+	}
+	return false
+}
+
+func xpathCompareAtoms(op string, a, b any) bool {
+	if op == "=" || op == "!=" {
+		var equal bool
+		_, aBool := a.(bool)
+		_, bBool := b.(bool)
+		_, aNum := a.(float64)
+		_, bNum := b.(float64)
+		switch {
+		case aBool || bBool:
+			equal = xpathToBool(a) == xpathToBool(b)
+		case aNum || bNum:
+			x, y := xpathToNumber(a), xpathToNumber(b)
+			if op == "!=" {
+				return x != y
+			}
+			return x == y
+		default:
+			equal = xpathToString(a) == xpathToString(b)
+		}
+		return equal == (op == "=")
+	}
+	x, y := xpathToNumber(a), xpathToNumber(b)
+	switch op {
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	}
+	return x >= y
+}
+
+// xpathStringValue returns the string-value of the node, the value of a leaf or the concatenated values of all descendants.
+func xpathStringValue(n XPathNode) string {
+	if v := n.Value(); v != nil {
+		return xpathValueString(v)
+	}
+	sb := strings.Builder{}
+	for _, c := range n.Children() {
+		sb.WriteString(xpathStringValue(c))
+	}
+	return sb.String()
+}
+
+func xpathValueString(tv *TypedValue) string {
+	switch tv.GetValue().(type) {
+	case *TypedValue_EmptyVal:
+		return ""
+	case *TypedValue_IdentityrefVal:
+		if tv.GetIdentityrefVal().GetPrefix() != "" {
+			return tv.GetIdentityrefVal().YangString()
+		}
+	}
+	return tv.ToString()
+}
+
+func xpathToBool(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case float64:
+		return x != 0 && !math.IsNaN(x)
+	case string:
+		return x != ""
+	case []XPathNode:
+		return len(x) > 0
+	}
+	return false
+}
+
+var xpathNumberRegexp = regexp.MustCompile(`^-?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+func xpathToNumber(v any) float64 {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	case float64:
+		return x
+	case string:
+		s := strings.TrimSpace(x)
+		if !xpathNumberRegexp.MatchString(s) {
+			return math.NaN()
+		}
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	case []XPathNode:
+		return xpathToNumber(xpathToString(x))
+	}
+	return math.NaN()
+}
+
+func xpathToString(v any) string {
+	switch x := v.(type) {
+	case bool:
+		return strconv.FormatBool(x)
+	case float64:
+		return xpathFormatNumber(x)
+	case string:
+		return x
+	case []XPathNode:
+		if len(x) == 0 {
+			return ""
+		}
+		return xpathStringValue(x[0])
+	}
+	return ""
+}
+
+func xpathFormatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type xpathFunction struct {
+	minArgs, maxArgs int
+	fn               func(c *xpathContext, args []any) (any, error)
+}
+
+// xpathUnlimitedArgs is used as maxArgs of functions with a variable number of arguments
+const xpathUnlimitedArgs = -1
+
+var xpathFunctions = map[string]xpathFunction{
+	// node-set functions
+	"last":     {0, 0, func(c *xpathContext, _ []any) (any, error) { return float64(c.size), nil }},
+	"position": {0, 0, func(c *xpathContext, _ []any) (any, error) { return float64(c.pos), nil }},
+	"count": {1, 1, func(_ *xpathContext, args []any) (any, error) {
+		nodes, err := xpathNodeSetArg(args[0], "count")
+		return float64(len(nodes)), err
+	}},
+	"local-name": {0, 1, xpathName(true)},
+	"name":       {0, 1, xpathName(false)},
+	// string functions
+	"string": {0, 1, func(c *xpathContext, args []any) (any, error) {
+		return xpathToString(xpathContextArg(c, args)), nil
+	}},
+	"concat": {2, xpathUnlimitedArgs, func(_ *xpathContext, args []any) (any, error) {
+		sb := strings.Builder{}
+		for _, a := range args {
+			sb.WriteString(xpathToString(a))
+		}
+		return sb.String(), nil
+	}},
+	"starts-with": {2, 2, func(_ *xpathContext, args []any) (any, error) {
+		return strings.HasPrefix(xpathToString(args[0]), xpathToString(args[1])), nil
+	}},
+	"contains": {2, 2, func(_ *xpathContext, args []any) (any, error) {
+		return strings.Contains(xpathToString(args[0]), xpathToString(args[1])), nil
+	}},
+	"substring-before": {2, 2, func(_ *xpathContext, args []any) (any, error) {
+		before, _, found := strings.Cut(xpathToString(args[0]), xpathToString(args[1]))
+		if !found {
+			return "", nil
+		}
+		return before, nil
+	}},
+	"substring-after": {2, 2, func(_ *xpathContext, args []any) (any, error) {
+		_, after, _ := strings.Cut(xpathToString(args[0]), xpathToString(args[1]))
+		return after, nil
+	}},
+	"substring": {2, 3, xpathSubstring},
+	"string-length": {0, 1, func(c *xpathContext, args []any) (any, error) {
+		return float64(utf8.RuneCountInString(xpathToString(xpathContextArg(c, args)))), nil
+	}},
+	"normalize-space": {0, 1, func(c *xpathContext, args []any) (any, error) {
+		return strings.Join(strings.Fields(xpathToString(xpathContextArg(c, args))), " "), nil
+	}},
+	"translate": {3, 3, xpathTranslate},
+	// boolean functions
+	"boolean": {1, 1, func(_ *xpathContext, args []any) (any, error) { return xpathToBool(args[0]), nil }},
+	"not":     {1, 1, func(_ *xpathContext, args []any) (any, error) { return !xpathToBool(args[0]), nil }},
+	"true":    {0, 0, func(*xpathContext, []any) (any, error) { return true, nil }},
+	"false":   {0, 0, func(*xpathContext, []any) (any, error) { return false, nil }},
+	// number functions
+	"number": {0, 1, func(c *xpathContext, args []any) (any, error) {
+		return xpathToNumber(xpathContextArg(c, args)), nil
+	}},
+	"sum": {1, 1, func(_ *xpathContext, args []any) (any, error) {
+		nodes, err := xpathNodeSetArg(args[0], "sum")
+		sum := 0.0
+		for _, n := range nodes {
+			sum += xpathToNumber(xpathStringValue(n))
+		}
+		return sum, err
+	}},
+	"floor":   {1, 1, func(_ *xpathContext, args []any) (any, error) { return math.Floor(xpathToNumber(args[0])), nil }},
+	"ceiling": {1, 1, func(_ *xpathContext, args []any) (any, error) { return math.Ceil(xpathToNumber(args[0])), nil }},
+	"round":   {1, 1, func(_ *xpathContext, args []any) (any, error) { return xpathRound(xpathToNumber(args[0])), nil }},
+	// YANG extension functions, RFC 7950 section 10
+	"current": {0, 0, func(c *xpathContext, _ []any) (any, error) { return []XPathNode{c.ev.current}, nil }},
+	"deref":   {1, 1, xpathDeref},
+	"derived-from": {2, 2, func(c *xpathContext, args []any) (any, error) {
+		return xpathDerivedFrom(c, args, false)
+	}},
+	"derived-from-or-self": {2, 2, func(c *xpathContext, args []any) (any, error) {
+		return xpathDerivedFrom(c, args, true)
+	}},
+	"re-match":   {2, 2, xpathReMatch},
+	"enum-value": {1, 1, xpathEnumValue},
+	"bit-is-set": {2, 2, xpathBitIsSet},
+}
+
+func (e *xpathFunctionCall) eval(c *xpathContext) (any, error) {
+	f := xpathFunctions[e.name]
+	args := make([]any, 0, len(e.args))
+	for _, a := range e.args {
+		v, err := a.eval(c)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	return f.fn(c, args)
+}
+
+// xpathContextArg returns the single optional argument, or the context node as node-set if it is omitted.
+func xpathContextArg(c *xpathContext, args []any) any {
+	if len(args) == 0 {
+		return []XPathNode{c.node}
+	}
+	return args[0]
+}
+
+func xpathNodeSetArg(v any, function string) ([]XPathNode, error) {
+	nodes, ok := v.([]XPathNode)
+	if !ok {
+		return nil, fmt.Errorf("%s() requires a node-set argument", function)
+	}
+	return nodes, nil
+}
+
+func xpathName(local bool) func(c *xpathContext, args []any) (any, error) {
+	return func(c *xpathContext, args []any) (any, error) {
+		nodes, err := xpathNodeSetArg(xpathContextArg(c, args), "name")
+		if err != nil || len(nodes) == 0 {
+			return "", err
+		}
+		if local {
+			_, name := splitModuleName(nodes[0].Name())
+			return name, nil
+		}
+		return nodes[0].Name(), nil
+	}
+}
+
+func xpathRound(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	return math.Floor(f + 0.5)
+}
+
+func xpathSubstring(_ *xpathContext, args []any) (any, error) {
+	runes := []rune(xpathToString(args[0]))
+	start := xpathRound(xpathToNumber(args[1]))
+	end := math.Inf(1)
+	if len(args) == 3 {
+		end = start + xpathRound(xpathToNumber(args[2]))
+	}
+	sb := strings.Builder{}
+	for i, r := range runes {
+		pos := float64(i + 1)
+		if pos >= start && pos < end {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), nil
+}
+
+func xpathTranslate(_ *xpathContext, args []any) (any, error) {
+	from := []rune(xpathToString(args[1]))
+	to := []rune(xpathToString(args[2]))
+	sb := strings.Builder{}
+	for _, r := range xpathToString(args[0]) {
+		idx := slices.Index(from, r)
+		switch {
+		case idx < 0:
+			sb.WriteRune(r)
+		case idx < len(to):
+			sb.WriteRune(to[idx])
+		}
+	}
+	return sb.String(), nil
+}
+
+// xpathLeafType returns the type of the leaf or leaf-list node, nil if it can not be determined.
+func xpathLeafType(c *xpathContext, n XPathNode) (*SchemaLeafType, error) {
+	if c.ev.env.Schema == nil {
+		return nil, nil
+	}
+	schema, err := c.ev.env.Schema(n.Path())
+	if err != nil {
+		return nil, err
+	}
+	return schema.LeafType(), nil
+}
+
+// xpathFindNodes returns the nodes below root that match the path, path elements without keys match all list entries.
+func xpathFindNodes(root XPathNode, p *Path) []XPathNode {
+	nodes := []XPathNode{root}
+	for _, pe := range p.GetElem() {
+		_, name := splitModuleName(pe.GetName())
+		next := []XPathNode{}
+		for _, n := range nodes {
+			for _, c := range n.Children() {
+				if _, cname := splitModuleName(c.Name()); cname == name && xpathKeysMatch(c, pe.GetKey()) {
+					next = append(next, c)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func xpathKeysMatch(n XPathNode, keys map[string]string) bool {
+	for k, v := range keys {
+		_, k = splitModuleName(k)
+		found := false
+		for _, c := range n.Children() {
+			if _, cname := splitModuleName(c.Name()); cname == k && xpathStringValue(c) == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// xpathDeref implements deref(), it returns the nodes referenced by the leafref or instance-identifier of the first node.
+func xpathDeref(c *xpathContext, args []any) (any, error) {
+	nodes, err := xpathNodeSetArg(args[0], "deref")
+	if err != nil || len(nodes) == 0 || nodes[0].Value() == nil {
+		return []XPathNode{}, err
+	}
+	if c.ev.env.Schema == nil {
+		return nil, fmt.Errorf("deref() requires a schema")
+	}
+	n := nodes[0]
+	lt, err := xpathLeafType(c, n)
+	if err != nil {
+		return nil, err
+	}
+	root := xpathRoot(n)
+	value := xpathStringValue(n)
+	result := []XPathNode{}
+	switch {
+	case lt.GetLeafref() != "":
+		paths, err := ResolveLeafref(lt.GetLeafref(), n.Path(), func(p *Path) ([]*TypedValue, error) {
+			tvs := []*TypedValue{}
+			for _, m := range xpathFindNodes(root, p) {
+				if m.Value() != nil {
+					tvs = append(tvs, m.Value())
+				}
+			}
+			return tvs, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			for _, m := range xpathFindNodes(root, p) {
+				if xpathStringValue(m) == value {
+					result = append(result, m)
+				}
+			}
+		}
+	case lt.GetType() == "instance-identifier":
+		p, err := ParseInstanceIdentifier(value, lt)
+		if err != nil {
+			return nil, fmt.Errorf("deref(): %w", err)
+		}
+		result = xpathFindNodes(root, p.StripPathElemPrefixPath())
+	}
+	return c.ev.sortNodes(result), nil
+}
+
+// xpathDerivedFrom implements derived-from() and derived-from-or-self().
+func xpathDerivedFrom(c *xpathContext, args []any, orSelf bool) (any, error) {
+	nodes, err := xpathNodeSetArg(args[0], "derived-from")
+	if err != nil {
+		return nil, err
+	}
+	_, identity := splitModuleName(xpathToString(args[1]))
+	for _, n := range nodes {
+		var value string
+		if ref := n.Value().GetIdentityrefVal(); ref != nil {
+			value = ref.GetValue()
+		} else {
+			_, value = splitModuleName(xpathStringValue(n))
+		}
+		if orSelf && value == identity {
+			return true, nil
+		}
+		if c.ev.env.IdentityBases == nil {
+			continue
+		}
+		// walk the base identities transitively
+		visited := map[string]bool{value: true}
+		queue := []string{value}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, base := range c.ev.env.IdentityBases(current) {
+				_, base = splitModuleName(base)
+				if base == identity {
+					return true, nil
+				}
+				if !visited[base] {
+					visited[base] = true
+					queue = append(queue, base)
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// xpathReMatch implements re-match(), the XSD pattern is translated into an anchored Go regular expression.
+// The compiled patterns are cached by the expression.
+func xpathReMatch(c *xpathContext, args []any) (any, error) {
+	pattern := xpathToString(args[1])
+	re, ok := c.ev.expr.patterns.Load(pattern)
+	if !ok {
+		compiled, err := compileXSDPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("re-match(): %w", err)
+		}
+		re, _ = c.ev.expr.patterns.LoadOrStore(pattern, compiled)
+	}
+	return re.(*regexp.Regexp).MatchString(xpathToString(args[0])), nil
+}
+
+// xpathEnumValue implements enum-value(), which returns the value assigned to the enum in the schema.
+// An error is returned if the schema does not provide the assigned values.
+func xpathEnumValue(c *xpathContext, args []any) (any, error) {
+	nodes, err := xpathNodeSetArg(args[0], "enum-value")
+	if err != nil || len(nodes) == 0 {
+		return math.NaN(), err
+	}
+	lt, err := xpathLeafType(c, nodes[0])
+	if err != nil {
+		return nil, err
+	}
+	name := xpathStringValue(nodes[0])
+	if !slices.Contains(lt.GetEnumNames(), name) {
+		return math.NaN(), nil
+	}
+	value, ok := lt.GetEnumValues()[name]
+	if !ok {
+		return nil, fmt.Errorf("enum-value(): the value assigned to enum %q of %s is unknown", name, nodes[0].Path().ToXPath(false))
+	}
+	return float64(value), nil
+}
+
+// xpathBitIsSet implements bit-is-set(), the value of a bits leaf is the space separated list of the set bits.
+func xpathBitIsSet(_ *xpathContext, args []any) (any, error) {
+	nodes, err := xpathNodeSetArg(args[0], "bit-is-set")
+	if err != nil || len(nodes) == 0 {
+		return false, err
+	}
+	return slices.Contains(strings.Fields(xpathStringValue(nodes[0])), xpathToString(args[1])), nil
+}
@@ -0,0 +1,201 @@
+package sdcpb
+
+import (
+	"errors"
+	"testing"
+)
+
+func testXPathTree(t *testing.T) XPathNode {
+	strVal := func(s string) *TypedValue {
+		return &TypedValue{Value: &TypedValue_StringVal{StringVal: s}}
+	}
+	uintVal := func(u uint64) *TypedValue {
+		return &TypedValue{Value: &TypedValue_UintVal{UintVal: u}}
+	}
+	return Updates{
+		{Path: mustParsePath(t, "/if:interface[name=eth0]/mtu"), Value: uintVal(1500)},
+		{Path: mustParsePath(t, "/interface[name=eth0]/type"), Value: &TypedValue{Value: &TypedValue_IdentityrefVal{IdentityrefVal: &IdentityRef{Value: "ethernetCsmacd", Prefix: "ianaift"}}}},
+		{Path: mustParsePath(t, "/interface[name=eth0]/admin-state"), Value: strVal("enable")},
+		{Path: mustParsePath(t, "/interface[name=eth0]/flags"), Value: strVal("up running")},
+		{Path: mustParsePath(t, "/interface[name=eth1]/mtu"), Value: uintVal(9000)},
+		{Path: mustParsePath(t, "/interface[name=eth1]/admin-state"), Value: strVal("disable")},
+		{Path: mustParsePath(t, "/interface[name=eth1]/tag"), Value: &TypedValue{Value: &TypedValue_LeaflistVal{LeaflistVal: &ScalarArray{Element: []*TypedValue{
+			strVal("core"), strVal("uplink"),
+		}}}}},
+		{Path: mustParsePath(t, "/system/mgmt-interface"), Value: strVal("eth1")},
+		{Path: mustParsePath(t, "/system/mgmt-path"), Value: strVal("/ex:interface[ex:name='eth0']/ex:mtu")},
+	}.DataRoot()
+}
+
+func testXPathEnv() *XPathEnv {
+	return &XPathEnv{
+		Schema: testSchemaLookup(map[string]*SchemaElem{
+			"/system/mgmt-interface": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "mgmt-interface", Type: &SchemaLeafType{
+				Type: "leafref", Leafref: "/interface/name",
+			}}}},
+			"/system/mgmt-path": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "mgmt-path", Type: &SchemaLeafType{
+				Type: "instance-identifier",
+			}}}},
+			"/interface/admin-state": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "admin-state", Type: &SchemaLeafType{
+				Type: "enumeration", EnumNames: []string{"enable", "disable"}, EnumValues: map[string]int32{"enable": 1, "disable": 2},
+			}}}},
+		}),
+		IdentityBases: func(identity string) []string {
+			return map[string][]string{
+				"ethernetCsmacd":      {"iana-interface-type"},
+				"iana-interface-type": {"if:interface-type"},
+			}[identity]
+		},
+	}
+}
+
+// xpathFind returns the node at the given path of the test tree.
+func xpathFind(t *testing.T, root XPathNode, xpath string) XPathNode {
+	t.Helper()
+	nodes := xpathFindNodes(root, mustParsePath(t, xpath))
+	if len(nodes) != 1 {
+		t.Fatalf("found %d nodes for %s", len(nodes), xpath)
+	}
+	return nodes[0]
+}
+
+func TestXPathExpr_Evaluate(t *testing.T) {
+	root := testXPathTree(t)
+	env := testXPathEnv()
+	mtu := xpathFind(t, root, "/interface[name=eth0]/mtu")
+	mgmt := xpathFind(t, root, "/system/mgmt-interface")
+
+	tests := []struct {
+		name    string
+		expr    string
+		node    XPathNode
+		want    any
+		wantErr bool
+	}{
+		{name: "arithmetic", expr: "1 + 2 * 3 - 4 div 2 mod 3", node: root, want: 5.0},
+		{name: "unary minus", expr: "-(2 - 5)", node: root, want: 3.0},
+		{name: "relative path", expr: ". = 1500", node: mtu, want: true},
+		{name: "parent and sibling", expr: "../admin-state", node: mtu, want: "enable"},
+		{name: "absolute path with predicate", expr: "/interface[name = 'eth1']/mtu", node: mtu, want: "9000"},
+		{name: "prefixed names", expr: "/if:interface[if:name='eth1']/if:mtu > 8000", node: root, want: true},
+		{name: "node-set comparison", expr: "/interface/mtu > 5000", node: root, want: true},
+		{name: "node-set inequality", expr: "/interface/mtu != 1500", node: root, want: true},
+		{name: "count", expr: "count(/interface)", node: root, want: 2.0},
+		{name: "sum", expr: "sum(/interface/mtu)", node: root, want: 10500.0},
+		{name: "leaf-list entries", expr: "count(/interface[name='eth1']/tag)", node: root, want: 2.0},
+		{name: "leaf-list value", expr: "/interface[tag='uplink']/name", node: root, want: "eth1"},
+		{name: "position", expr: "/interface[2]/name", node: root, want: "eth1"},
+		{name: "last", expr: "/interface[last()]/name", node: root, want: "eth1"},
+		{name: "descendant", expr: "count(//mtu)", node: root, want: 2.0},
+		{name: "ancestor", expr: "name(ancestor::*[1])", node: mtu, want: "interface"},
+		{name: "preceding sibling", expr: "name(preceding-sibling::*[1])", node: mtu, want: "name"},
+		{name: "following sibling", expr: "name(following-sibling::*[1])", node: mtu, want: "type"},
+		{name: "union", expr: "count(/interface/mtu | /interface/mtu | /system/*)", node: root, want: 4.0},
+		{name: "union in document order", expr: "/system/mgmt-interface | /interface[name='eth1']/mtu | /interface[name='eth0']/mtu", node: root, want: "1500"},
+		{name: "following", expr: "count(following::mtu) = 1 and name(following::*[1]) = 'type'", node: mtu, want: true},
+		{name: "preceding", expr: "name(preceding::*[1]) = 'name' and count(preceding::interface) = 0", node: mtu, want: true},
+		{name: "preceding of a later node", expr: "count(/system/preceding::interface)", node: root, want: 2.0},
+		{name: "current in predicate", expr: "/interface[name = current()]/mtu", node: mgmt, want: "9000"},
+		{name: "string functions", expr: "concat(substring-before('a-b', '-'), substring-after('a-b', '-'), substring('12345', 2, 3))", node: root, want: "ab234"},
+		{name: "translate and normalize", expr: "translate(normalize-space('  a  b '), 'ab', 'B')", node: root, want: "B "},
+		{name: "string-length", expr: "string-length(/interface[name='eth0']/admin-state)", node: root, want: 6.0},
+		{name: "boolean functions", expr: "not(false()) and boolean('x') and true()", node: root, want: true},
+		{name: "number functions", expr: "floor(2.5) + ceiling(2.5) + round(2.5) + number('1')", node: root, want: 9.0},
+		{name: "number formatting", expr: "string(1 div 0)", node: root, want: "Infinity"},
+		{name: "not a number", expr: "number('abc') = number('abc')", node: root, want: false},
+		{name: "starts-with and contains", expr: "starts-with('ethernet', 'eth') and contains('ethernet', 'erne')", node: root, want: true},
+		{name: "deref leafref", expr: "deref(.)/../mtu", node: mgmt, want: "9000"},
+		{name: "deref instance-identifier", expr: "deref(/system/mgmt-path)", node: root, want: "1500"},
+		{name: "derived-from", expr: "derived-from(/interface/type, 'ianaift:iana-interface-type')", node: root, want: true},
+		{name: "derived-from transitive", expr: "derived-from(/interface/type, 'if:interface-type')", node: root, want: true},
+		{name: "derived-from excludes self", expr: "derived-from(/interface/type, 'ianaift:ethernetCsmacd')", node: root, want: false},
+		{name: "derived-from-or-self", expr: "derived-from-or-self(/interface/type, 'ianaift:ethernetCsmacd')", node: root, want: true},
+		{name: "re-match", expr: "re-match('eth0', 'eth[0-9]+') and not(re-match('eth0x', 'eth[0-9]+'))", node: root, want: true},
+		{name: "re-match xsd pattern", expr: `re-match('if-1', '\i\c*') and not(re-match('1if', '\i\c*')) and re-match('^x', '^x')`, node: root, want: true},
+		{name: "re-match class subtraction", expr: "re-match('bcd', '[a-z-[aeiou]]+') and not(re-match('bad', '[a-z-[aeiou]]+'))", node: root, want: true},
+		{name: "re-match invalid pattern", expr: "re-match('a', '[a')", node: root, wantErr: true},
+		{name: "enum-value", expr: "enum-value(/interface[name='eth1']/admin-state)", node: root, want: 2.0},
+		{name: "bit-is-set", expr: "bit-is-set(/interface[name='eth0']/flags, 'running') and not(bit-is-set(/interface[name='eth0']/flags, 'down'))", node: root, want: true},
+		{name: "unknown function", expr: "foo(1)", node: root, wantErr: true},
+		{name: "wrong argument count", expr: "count()", node: root, wantErr: true},
+		{name: "variables", expr: "$x = 1", node: root, wantErr: true},
+		{name: "unbalanced", expr: "(1 + 2", node: root, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := CompileXPath(tt.expr)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			got, err := expr.Evaluate(tt.node, env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			// node-sets are compared by their string value
+			if _, isSet := got.([]XPathNode); isSet {
+				got = xpathToString(got)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXPathExpr_EnumValueUnknown(t *testing.T) {
+	root := testXPathTree(t)
+	env := &XPathEnv{Schema: testSchemaLookup(map[string]*SchemaElem{
+		"/interface/admin-state": {Schema: &SchemaElem_Field{Field: &LeafSchema{Name: "admin-state", Type: &SchemaLeafType{
+			Type: "enumeration", EnumNames: []string{"enable", "disable"},
+		}}}},
+	})}
+	expr, err := CompileXPath("enum-value(/interface[name='eth1']/admin-state)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := expr.Evaluate(root, env); err == nil {
+		t.Errorf("Evaluate() = %v, wanted error for unknown enum values", v)
+	}
+}
+
+func TestValidateMustStatements(t *testing.T) {
+	env := testXPathEnv()
+	schema := env.Schema
+	env.Schema = func(p *Path) (*SchemaElem, error) {
+		if p.ToXPath(true) == "/interface/mtu" {
+			return &SchemaElem{Schema: &SchemaElem_Field{Field: &LeafSchema{
+				Name: "mtu",
+				MustStatements: []*MustStatement{
+					{Statement: ". <= 9000"},
+					{Statement: "../admin-state = 'enable' or . >= 9000", Error: "disabled interfaces require jumbo frames"},
+				},
+			}}}, nil
+		}
+		return schema(p)
+	}
+
+	tree := Updates{
+		{Path: mustParsePath(t, "/interface[name=eth0]/mtu"), Value: &TypedValue{Value: &TypedValue_UintVal{UintVal: 1500}}},
+		{Path: mustParsePath(t, "/interface[name=eth0]/admin-state"), Value: &TypedValue{Value: &TypedValue_StringVal{StringVal: "disable"}}},
+		{Path: mustParsePath(t, "/interface[name=eth1]/mtu"), Value: &TypedValue{Value: &TypedValue_UintVal{UintVal: 9000}}},
+	}
+	err := ValidateMustStatements(tree, env)
+	var mse *MustStatementError
+	if !errors.As(err, &mse) {
+		t.Fatalf("expected a MustStatementError, got %v", err)
+	}
+	if want := "/interface[name=eth0]/mtu: disabled interfaces require jumbo frames"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+
+	tree[1].Value = &TypedValue{Value: &TypedValue_StringVal{StringVal: "enable"}}
+	if err := ValidateMustStatements(tree, env); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
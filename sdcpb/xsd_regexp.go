@@ -0,0 +1,256 @@
+package sdcpb
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// compileXSDPattern compiles the XML Schema regular expression, as used by YANG patterns and re-match(),
+// into an anchored Go regular expression. The multi-character escapes \i, \c, \d, \w and \s, Unicode block
+// escapes like \p{IsBasicLatin} and character class subtraction are translated into their Go equivalents.
+// See https://www.w3.org/TR/xmlschema-2/#regexs
+func compileXSDPattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); {
+		switch c := pattern[i]; c {
+		case '\\':
+			esc, next, err := xsdEscape(pattern, i, false)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			b.WriteString(esc)
+			i = next
+		case '[':
+			class, next, err := xsdClass(pattern, i)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			b.WriteString(class)
+			i = next
+		case '^', '$':
+			// anchors do not exist in XSD, both are ordinary characters
+			b.WriteByte('\\')
+			b.WriteByte(c)
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	re, err := regexp.Compile("^(?:" + b.String() + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// xsdMultiCharEscapes holds the Go equivalents of the XSD multi-character escapes, outside and inside of a
+// character class. Negated escapes can not be expressed inside a Go character class.
+var xsdMultiCharEscapes = map[byte][2]string{
+	'i': {`[\p{L}_:]`, `\p{L}_:`},
+	'I': {`[^\p{L}_:]`, ""},
+	'c': {`[\-.0-9:_\p{L}\p{Mn}\p{Mc}\p{Nd}\x{B7}]`, `\-.0-9:_\p{L}\p{Mn}\p{Mc}\p{Nd}\x{B7}`},
+	'C': {`[^\-.0-9:_\p{L}\p{Mn}\p{Mc}\p{Nd}\x{B7}]`, ""},
+	'd': {`\p{Nd}`, `\p{Nd}`},
+	'D': {`\P{Nd}`, `\P{Nd}`},
+	'w': {`[^\p{P}\p{Z}\p{C}]`, ""},
+	'W': {`[\p{P}\p{Z}\p{C}]`, `\p{P}\p{Z}\p{C}`},
+	's': {`[ \t\n\r]`, ` \t\n\r`},
+	'S': {`[^ \t\n\r]`, ""},
+}
+
+// xsdBlocks holds the ranges of the Unicode blocks supported by \p{Is...}, Go does not know Unicode blocks.
+var xsdBlocks = map[string][2]rune{
+	"BasicLatin":                 {0x0000, 0x007F},
+	"Latin-1Supplement":          {0x0080, 0x00FF},
+	"LatinExtended-A":            {0x0100, 0x017F},
+	"LatinExtended-B":            {0x0180, 0x024F},
+	"IPAExtensions":              {0x0250, 0x02AF},
+	"SpacingModifierLetters":     {0x02B0, 0x02FF},
+	"CombiningDiacriticalMarks":  {0x0300, 0x036F},
+	"Greek":                      {0x0370, 0x03FF},
+	"Cyrillic":                   {0x0400, 0x04FF},
+	"Armenian":                   {0x0530, 0x058F},
+	"Hebrew":                     {0x0590, 0x05FF},
+	"Arabic":                     {0x0600, 0x06FF},
+	"Devanagari":                 {0x0900, 0x097F},
+	"Thai":                       {0x0E00, 0x0E7F},
+	"LatinExtendedAdditional":    {0x1E00, 0x1EFF},
+	"GreekExtended":              {0x1F00, 0x1FFF},
+	"GeneralPunctuation":         {0x2000, 0x206F},
+	"SuperscriptsandSubscripts":  {0x2070, 0x209F},
+	"CurrencySymbols":            {0x20A0, 0x20CF},
+	"LetterlikeSymbols":          {0x2100, 0x214F},
+	"NumberForms":                {0x2150, 0x218F},
+	"Arrows":                     {0x2190, 0x21FF},
+	"MathematicalOperators":      {0x2200, 0x22FF},
+	"BoxDrawing":                 {0x2500, 0x257F},
+	"GeometricShapes":            {0x25A0, 0x25FF},
+	"CJKSymbolsandPunctuation":   {0x3000, 0x303F},
+	"Hiragana":                   {0x3040, 0x309F},
+	"Katakana":                   {0x30A0, 0x30FF},
+	"CJKUnifiedIdeographs":       {0x4E00, 0x9FFF},
+	"HangulSyllables":            {0xAC00, 0xD7AF},
+	"PrivateUse":                 {0xE000, 0xF8FF},
+	"HalfwidthandFullwidthForms": {0xFF00, 0xFFEF},
+	"Specials":                   {0xFFF0, 0xFFFF},
+}
+
+// xsdEscape translates the escape starting at s[i] and returns the index after it.
+func xsdEscape(s string, i int, inClass bool) (string, int, error) {
+	if i+1 >= len(s) {
+		return "", 0, fmt.Errorf("trailing backslash")
+	}
+	c := s[i+1]
+	if esc, ok := xsdMultiCharEscapes[c]; ok {
+		if inClass {
+			if esc[1] == "" {
+				return "", 0, fmt.Errorf(`\%c is not supported inside a character class`, c)
+			}
+			return esc[1], i + 2, nil
+		}
+		return esc[0], i + 2, nil
+	}
+	if c == 'p' || c == 'P' {
+		end := strings.IndexByte(s[i:], '}')
+		if i+2 >= len(s) || s[i+2] != '{' || end < 0 {
+			return "", 0, fmt.Errorf(`invalid \%c escape`, c)
+		}
+		end += i
+		name := s[i+3 : end]
+		block, isBlock := strings.CutPrefix(name, "Is")
+		if !isBlock {
+			// general categories are named alike in Go
+			return s[i : end+1], end + 1, nil
+		}
+		rng, ok := xsdBlocks[block]
+		if !ok {
+			return "", 0, fmt.Errorf("unsupported Unicode block %q", block)
+		}
+		ranges := fmt.Sprintf(`\x{%X}-\x{%X}`, rng[0], rng[1])
+		switch {
+		case inClass && c == 'P':
+			return "", 0, fmt.Errorf(`\P{Is%s} is not supported inside a character class`, block)
+		case inClass:
+			return ranges, end + 1, nil
+		case c == 'P':
+			return "[^" + ranges + "]", end + 1, nil
+		}
+		return "[" + ranges + "]", end + 1, nil
+	}
+	// single character escapes have the same meaning in Go
+	_, size := utf8.DecodeRuneInString(s[i+1:])
+	return s[i : i+1+size], i + 1 + size, nil
+}
+
+// xsdClass translates the character class starting at s[i] == '[' and returns the index after it.
+// A subtraction is resolved into the explicit ranges of the resulting class.
+func xsdClass(s string, i int) (string, int, error) {
+	var b strings.Builder
+	b.WriteByte('[')
+	i++
+	if i < len(s) && s[i] == '^' {
+		b.WriteByte('^')
+		i++
+	}
+	for i < len(s) {
+		switch {
+		case s[i] == ']':
+			b.WriteByte(']')
+			return b.String(), i + 1, nil
+		case s[i] == '-' && i+1 < len(s) && s[i+1] == '[':
+			b.WriteByte(']')
+			sub, next, err := xsdClass(s, i+1)
+			if err != nil {
+				return "", 0, err
+			}
+			if next >= len(s) || s[next] != ']' {
+				return "", 0, fmt.Errorf("a subtraction must end the character class")
+			}
+			class, err := xsdSubtractClass(b.String(), sub)
+			return class, next + 1, err
+		case s[i] == '\\':
+			esc, next, err := xsdEscape(s, i, true)
+			if err != nil {
+				return "", 0, err
+			}
+			b.WriteString(esc)
+			i = next
+		case s[i] == '[':
+			// keep Go from reading a POSIX class like [:alpha:]
+			b.WriteString(`\[`)
+			i++
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated character class")
+}
+
+// xsdSubtractClass returns a Go character class matching the characters of class that are not matched by sub.
+func xsdSubtractClass(class, sub string) (string, error) {
+	a, err := xsdClassRanges(class)
+	if err != nil {
+		return "", err
+	}
+	b, err := xsdClassRanges(sub)
+	if err != nil {
+		return "", err
+	}
+	ranges := subtractRuneRanges(a, b)
+	if len(ranges) == 0 {
+		// the empty class, which never matches
+		return `[^\x{0}-\x{10FFFF}]`, nil
+	}
+	var result strings.Builder
+	result.WriteByte('[')
+	for j := 0; j < len(ranges); j += 2 {
+		fmt.Fprintf(&result, `\x{%X}-\x{%X}`, ranges[j], ranges[j+1])
+	}
+	result.WriteByte(']')
+	return result.String(), nil
+}
+
+// xsdClassRanges returns the sorted rune ranges, as lo-hi pairs, matched by the Go character class.
+func xsdClassRanges(class string) ([]rune, error) {
+	re, err := syntax.Parse(class, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case re.Op == syntax.OpCharClass:
+		return re.Rune, nil
+	case re.Op == syntax.OpLiteral && len(re.Rune) == 1:
+		return []rune{re.Rune[0], re.Rune[0]}, nil
+	case re.Op == syntax.OpAnyChar:
+		return []rune{0, utf8.MaxRune}, nil
+	case re.Op == syntax.OpNoMatch:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected character class %s", class)
+}
+
+// subtractRuneRanges removes the sorted ranges b from the sorted ranges a.
+func subtractRuneRanges(a, b []rune) []rune {
+	var result []rune
+	for i := 0; i < len(a); i += 2 {
+		lo, hi := a[i], a[i+1]
+		for j := 0; j < len(b) && lo <= hi; j += 2 {
+			if b[j+1] < lo || b[j] > hi {
+				continue
+			}
+			if b[j] > lo {
+				result = append(result, lo, b[j]-1)
+			}
+			lo = b[j+1] + 1
+		}
+		if lo <= hi {
+			result = append(result, lo, hi)
+		}
+	}
+	return result
+}
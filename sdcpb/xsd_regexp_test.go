@@ -0,0 +1,66 @@
+package sdcpb
+
+import "testing"
+
+func TestCompileXSDPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		match   []string
+		noMatch []string
+		wantErr bool
+	}{
+		{name: "anchored", pattern: "eth[0-9]+", match: []string{"eth0", "eth12"}, noMatch: []string{"xeth0", "eth0x"}},
+		{name: "alternatives are anchored", pattern: "a|b", match: []string{"a", "b"}, noMatch: []string{"ab"}},
+		{name: "caret and dollar are literals", pattern: "^a$", match: []string{"^a$"}, noMatch: []string{"a"}},
+		{name: "caret within a class is a literal", pattern: "[a^b]", match: []string{"^", "b"}, noMatch: []string{"c"}},
+		{name: "negated class with caret", pattern: "[^a^b]", match: []string{"c"}, noMatch: []string{"^", "a"}},
+		{name: "escaped caret and dollar", pattern: `\^a\$`, match: []string{"^a$"}, noMatch: []string{"a"}},
+		{name: "name start and name chars", pattern: `\i\c*`, match: []string{"_if-name.1", "é:x"}, noMatch: []string{"1abc", "-a"}},
+		{name: "negated name start", pattern: `\I.*`, match: []string{"1abc"}, noMatch: []string{"abc"}},
+		{name: "unicode digits", pattern: `\d+`, match: []string{"12", "١٢"}, noMatch: []string{"a"}},
+		{name: "word chars", pattern: `\w+`, match: []string{"abc1"}, noMatch: []string{"a b", "a-b"}},
+		{name: "xsd whitespace", pattern: `a\sb`, match: []string{"a b", "a\tb"}, noMatch: []string{"a\fb"}},
+		{name: "name start in class", pattern: `[\i0-9]+`, match: []string{"a1:"}, noMatch: []string{"-"}},
+		{name: "unicode block", pattern: `\p{IsBasicLatin}+`, match: []string{"abc"}, noMatch: []string{"äbc"}},
+		{name: "negated unicode block", pattern: `\P{IsBasicLatin}`, match: []string{"ä"}, noMatch: []string{"a"}},
+		{name: "unicode block in class", pattern: `[\p{IsGreek}a]+`, match: []string{"aαβ"}, noMatch: []string{"b"}},
+		{name: "category", pattern: `\p{Lu}\p{Ll}*`, match: []string{"Eth"}, noMatch: []string{"eth"}},
+		{name: "subtraction", pattern: "[a-z-[aeiou]]+", match: []string{"bcd"}, noMatch: []string{"bad"}},
+		{name: "nested subtraction", pattern: "[a-z-[a-f-[c]]]+", match: []string{"cxyz"}, noMatch: []string{"a"}},
+		{name: "negated subtraction", pattern: "[^a-z-[0-9]]", match: []string{"A"}, noMatch: []string{"a", "5"}},
+		{name: "subtraction of everything", pattern: "[a-[a]]?", match: []string{""}, noMatch: []string{"a"}},
+		{name: "subtraction with escapes", pattern: `[\d-[0]]+`, match: []string{"19"}, noMatch: []string{"10"}},
+		{name: "escaped bracket in class", pattern: `[\[\]]+`, match: []string{"[]"}, noMatch: []string{"a"}},
+		{name: "bracket in class", pattern: `[[:a]+`, match: []string{"[:a"}, noMatch: []string{"b"}},
+		{name: "negated escape in class", pattern: `[\S]`, wantErr: true},
+		{name: "unknown block", pattern: `\p{IsKlingon}`, wantErr: true},
+		{name: "unterminated class", pattern: `[a-z`, wantErr: true},
+		{name: "subtraction not at the end", pattern: `[a-z-[b]c]`, wantErr: true},
+		{name: "trailing backslash", pattern: `a\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := compileXSDPattern(tt.pattern)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("wanted error, got %s", re)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.match {
+				if !re.MatchString(s) {
+					t.Errorf("%s (%s) does not match %q", tt.pattern, re, s)
+				}
+			}
+			for _, s := range tt.noMatch {
+				if re.MatchString(s) {
+					t.Errorf("%s (%s) matches %q", tt.pattern, re, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package tree_persist
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The streaming format of an Intent is a sequence of length-delimited frames.
+// The first frame is the header, an Intent message without its root. It is followed by one frame per
+// TreeElement in depth-first order, each consisting of the uvarint encoded depth of the element
+// (0 for the children of the root) and the TreeElement message without its children.
+// This allows to write and read arbitrarily large intents without holding the whole tree in memory.
+
+// IntentWriter writes an Intent in the streaming format.
+type IntentWriter struct {
+	w     *bufio.Writer
+	depth int
+}
+
+// NewIntentWriter writes the header of the intent, its root is ignored, and returns a writer for the tree elements.
+// Flush must be called once all elements are written.
+func NewIntentWriter(w io.Writer, header *Intent) (*IntentWriter, error) {
+	iw := &IntentWriter{w: bufio.NewWriter(w), depth: -1}
+	// the header shares all fields but the root with the given intent instead of copying the whole tree
+	h := &Intent{}
+	hm := h.ProtoReflect()
+	rootField := hm.Descriptor().Fields().ByName("root")
+	header.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd != rootField {
+			hm.Set(fd, v)
+		}
+		return true
+	})
+	if _, err := protodelim.MarshalTo(iw.w, h); err != nil {
+		return nil, fmt.Errorf("unable to write intent header: %w", err)
+	}
+	return iw, nil
+}
+
+// WriteElement writes a single element with the given name and marshalled leaf value at the given depth.
+// Elements must be written in depth-first order, an element can only be one level below the previously written one.
+func (iw *IntentWriter) WriteElement(depth int, name string, leafVariant []byte) error {
+	if depth < 0 || depth > iw.depth+1 {
+		return fmt.Errorf("invalid depth %d for element %q after depth %d", depth, name, iw.depth)
+	}
+	if _, err := iw.w.Write(binary.AppendUvarint(nil, uint64(depth))); err != nil {
+		return err
+	}
+	if _, err := protodelim.MarshalTo(iw.w, &TreeElement{Name: name, LeafVariant: leafVariant}); err != nil {
+		return err
+	}
+	iw.depth = depth
+	return nil
+}
+
+// WriteTree writes the children of the given TreeElement, which is considered the root, recursively.
+func (iw *IntentWriter) WriteTree(root *TreeElement) error {
+	for _, c := range root.GetChilds() {
+		if err := iw.writeSubtree(0, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (iw *IntentWriter) writeSubtree(depth int, te *TreeElement) error {
+	if err := iw.WriteElement(depth, te.GetName(), te.GetLeafVariant()); err != nil {
+		return err
+	}
+	for _, c := range te.GetChilds() {
+		if err := iw.writeSubtree(depth+1, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (iw *IntentWriter) Flush() error {
+	return iw.w.Flush()
+}
+
+// WriteIntent writes the whole intent in the streaming format.
+func WriteIntent(w io.Writer, intent *Intent) error {
+	iw, err := NewIntentWriter(w, intent)
+	if err != nil {
+		return err
+	}
+	if err := iw.WriteTree(intent.GetRoot()); err != nil {
+		return err
+	}
+	return iw.Flush()
+}
+
+// StreamElement is a TreeElement read from the stream, without its children.
+type StreamElement struct {
+	// Depth is the depth of the element, 0 for the children of the root.
+	Depth int
+	// Path holds the names of the ancestors and the element itself. It is only valid until
+	// the next element is read, use slices.Clone to keep it.
+	Path        []string
+	leafVariant []byte
+}
+
+// Name returns the name of the element.
+func (se *StreamElement) Name() string {
+	return se.Path[len(se.Path)-1]
+}
+
+// HasValue returns true if the element carries a leaf value.
+func (se *StreamElement) HasValue() bool {
+	return len(se.leafVariant) > 0
+}
+
+// LeafVariant returns the marshalled leaf value of the element.
+func (se *StreamElement) LeafVariant() []byte {
+	return se.leafVariant
+}
+
+// Value unmarshals the leaf value of the element, it returns nil if the element carries no value.
+func (se *StreamElement) Value() (*sdcpb.TypedValue, error) {
+	if !se.HasValue() {
+		return nil, nil
+	}
+	tv := &sdcpb.TypedValue{}
+	if err := proto.Unmarshal(se.leafVariant, tv); err != nil {
+		return nil, err
+	}
+	return tv, nil
+}
+
+// IntentReader reads an Intent in the streaming format.
+type IntentReader struct {
+	r      *bufio.Reader
+	header *Intent
+}
+
+// NewIntentReader reads the header of the stream.
+func NewIntentReader(r io.Reader) (*IntentReader, error) {
+	ir := &IntentReader{r: bufio.NewReader(r), header: &Intent{}}
+	if err := protodelim.UnmarshalFrom(ir.r, ir.header); err != nil {
+		return nil, fmt.Errorf("unable to read intent header: %w", err)
+	}
+	return ir, nil
+}
+
+// Header returns the intent read from the header, without its root.
+func (ir *IntentReader) Header() *Intent {
+	return ir.header
+}
+
+// Elements returns an iterator over the elements of the stream in depth-first order.
+// Iteration stops after the first error. The stream can only be iterated once.
+func (ir *IntentReader) Elements() iter.Seq2[*StreamElement, error] {
+	return func(yield func(*StreamElement, error) bool) {
+		se := &StreamElement{}
+		for {
+			depth, err := binary.ReadUvarint(ir.r)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if depth > uint64(len(se.Path)) {
+				yield(nil, fmt.Errorf("invalid depth %d after depth %d", depth, len(se.Path)-1))
+				return
+			}
+			te := &TreeElement{}
+			if err := protodelim.UnmarshalFrom(ir.r, te); err != nil {
+				yield(nil, fmt.Errorf("unable to read tree element: %w", err))
+				return
+			}
+			se.Depth = int(depth)
+			se.Path = append(se.Path[:depth], te.GetName())
+			se.leafVariant = te.GetLeafVariant()
+			if !yield(se, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ReadIntent reads the whole intent from the streaming format.
+func ReadIntent(r io.Reader) (*Intent, error) {
+	ir, err := NewIntentReader(r)
+	if err != nil {
+		return nil, err
+	}
+	root := &TreeElement{}
+	// stack holds the ancestors of the next element, stack[0] is the root
+	stack := []*TreeElement{root}
+	for se, err := range ir.Elements() {
+		if err != nil {
+			return nil, err
+		}
+		te := &TreeElement{Name: se.Name(), LeafVariant: se.LeafVariant()}
+		parent := stack[se.Depth]
+		parent.Childs = append(parent.Childs, te)
+		stack = append(stack[:se.Depth+1], te)
+	}
+	intent := ir.Header()
+	intent.Root = root
+	return intent, nil
+}
@@ -0,0 +1,133 @@
+package tree_persist
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIntentStreamRoundTrip(t *testing.T) {
+	keyLookup := testKeyLookup(map[string][]string{"/interface": {"name"}})
+	intent, err := FromUpdates("intent1", 5, []*sdcpb.Update{
+		{
+			Path:  mustParsePath(t, "/interface[name=ethernet-1/1]/description"),
+			Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "uplink"}},
+		},
+		{
+			Path:  mustParsePath(t, "/interface[name=ethernet-1/2]/description"),
+			Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "downlink"}},
+		},
+		{
+			Path:  mustParsePath(t, "/system/name"),
+			Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "leaf1"}},
+		},
+	}, keyLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intent.ExplicitDeletes = []*sdcpb.Path{mustParsePath(t, "/interface[name=ethernet-1/3]")}
+	intent.Orphan = true
+
+	buf := &bytes.Buffer{}
+	if err := WriteIntent(buf, intent); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	got, err := ReadIntent(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, intent) {
+		t.Errorf("ReadIntent() = %v, want %v", got, intent)
+	}
+
+	ir, err := NewIntentReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ir.Header().GetIntentName() != "intent1" || ir.Header().GetRoot() != nil {
+		t.Errorf("unexpected header %v", ir.Header())
+	}
+	leafs := []string{}
+	for se, err := range ir.Elements() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !se.HasValue() {
+			continue
+		}
+		tv, err := se.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		leafs = append(leafs, strings.Join(se.Path, "/")+": "+tv.ToString())
+		if se.Path[0] == "system" {
+			break
+		}
+	}
+	want := []string{
+		"interface/ethernet-1/1/description: uplink",
+		"interface/ethernet-1/2/description: downlink",
+		"system/name: leaf1",
+	}
+	if !slices.Equal(leafs, want) {
+		t.Errorf("elements = %v, want %v", leafs, want)
+	}
+
+	if _, err := ReadIntent(bytes.NewReader(data[:len(data)-3])); err == nil {
+		t.Errorf("expected error for a truncated stream")
+	}
+}
+
+func TestIntentWriter_InvalidDepth(t *testing.T) {
+	iw, err := NewIntentWriter(&bytes.Buffer{}, &Intent{IntentName: "intent1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.WriteElement(0, "interface", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.WriteElement(2, "description", nil); err == nil {
+		t.Errorf("expected error when skipping a level")
+	}
+}
+
+func TestNewIntentWriter_HeaderWithoutRoot(t *testing.T) {
+	intent := &Intent{
+		IntentName:      "intent1",
+		Priority:        10,
+		ExplicitDeletes: []*sdcpb.Path{{Elem: []*sdcpb.PathElem{{Name: "system"}}}},
+		NonRevertive:    true,
+		Orphan:          true,
+		Root:            &TreeElement{Childs: []*TreeElement{{Name: "system"}}},
+	}
+	buf := &bytes.Buffer{}
+	iw, err := NewIntentWriter(buf, intent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	header := &Intent{}
+	if err := protodelim.UnmarshalFrom(buf, header); err != nil {
+		t.Fatal(err)
+	}
+	if header.GetRoot() != nil {
+		t.Errorf("header frame carries a root: %v", header.GetRoot())
+	}
+	want := proto.CloneOf(intent)
+	want.Root = nil
+	if !proto.Equal(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if intent.GetRoot() == nil {
+		t.Errorf("the root of the given intent was modified")
+	}
+}
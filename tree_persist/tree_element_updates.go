@@ -0,0 +1,227 @@
+package tree_persist
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// errNoKeyLookup is returned when the KeyNamesLookupFunc required to map list keys is missing.
+var errNoKeyLookup = errors.New("a key names lookup is required to map list keys")
+
+// KeyNamesLookupFunc returns the key names of the list referenced by the given path, or nil if the path
+// does not reference a list. The path does not carry the keys of the referenced list itself.
+type KeyNamesLookupFunc func(p *sdcpb.Path) ([]string, error)
+
+// KeyNamesFromSchema adapts a schema lookup into a KeyNamesLookupFunc.
+func KeyNamesFromSchema(lookup sdcpb.SchemaLookupFunc) KeyNamesLookupFunc {
+	return func(p *sdcpb.Path) ([]string, error) {
+		schema, err := lookup(p)
+		if err != nil {
+			return nil, err
+		}
+		keys := schema.GetContainer().GetKeys()
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		result := make([]string, 0, len(keys))
+		for _, k := range keys {
+			result = append(result, k.GetName())
+		}
+		return result, nil
+	}
+}
+
+// ToUpdates converts the tree below the TreeElement into a flat list of updates, one per leaf.
+// The TreeElement itself is considered the root, its name is not part of the resulting paths.
+// List keys are persisted as nested levels, one level per key in alphabetical order of the key names,
+// the keyLookup is used to identify these levels. A nil keyLookup returns an error.
+func (te *TreeElement) ToUpdates(keyLookup KeyNamesLookupFunc) ([]*sdcpb.Update, error) {
+	if keyLookup == nil {
+		return nil, errNoKeyLookup
+	}
+	result := []*sdcpb.Update{}
+	for _, c := range te.GetChilds() {
+		var err error
+		result, err = c.appendUpdates(&sdcpb.Path{IsRootBased: true}, keyLookup, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (te *TreeElement) appendUpdates(parent *sdcpb.Path, keyLookup KeyNamesLookupFunc, result []*sdcpb.Update) ([]*sdcpb.Update, error) {
+	p := parent.CopyPathAddElem(&sdcpb.PathElem{Name: te.GetName()})
+	keyNames, err := keyLookup(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyNames) == 0 {
+		return te.appendEntryUpdates(p, keyLookup, result)
+	}
+	keyNames = slices.Sorted(slices.Values(keyNames))
+	return te.appendKeyLevel(te.GetName(), parent, map[string]string{}, keyNames, keyLookup, result)
+}
+
+// appendKeyLevel descends the key levels of a list, collecting the key values, until all keys are resolved.
+func (te *TreeElement) appendKeyLevel(listName string, parent *sdcpb.Path, keys map[string]string, keyNames []string, keyLookup KeyNamesLookupFunc, result []*sdcpb.Update) ([]*sdcpb.Update, error) {
+	if len(keyNames) == 0 {
+		p := parent.CopyPathAddElem(sdcpb.NewPathElem(listName, keys))
+		return te.appendEntryUpdates(p, keyLookup, result)
+	}
+	for _, c := range te.GetChilds() {
+		childKeys := make(map[string]string, len(keys)+1)
+		for k, v := range keys {
+			childKeys[k] = v
+		}
+		childKeys[keyNames[0]] = c.GetName()
+		var err error
+		result, err = c.appendKeyLevel(listName, parent, childKeys, keyNames[1:], keyLookup, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// appendEntryUpdates adds the leaf value of the TreeElement, if any, and processes all children.
+func (te *TreeElement) appendEntryUpdates(p *sdcpb.Path, keyLookup KeyNamesLookupFunc, result []*sdcpb.Update) ([]*sdcpb.Update, error) {
+	if len(te.GetLeafVariant()) > 0 {
+		tv := &sdcpb.TypedValue{}
+		if err := proto.Unmarshal(te.GetLeafVariant(), tv); err != nil {
+			return nil, fmt.Errorf("%s: unable to unmarshal leaf value: %w", p.ToXPath(false), err)
+		}
+		result = append(result, &sdcpb.Update{Path: p, Value: tv})
+	}
+	for _, c := range te.GetChilds() {
+		var err error
+		result, err = c.appendUpdates(p, keyLookup, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// DiffTreeElements compares the leafs of the main and the candidate tree, see sdcpb.DiffUpdates.
+func DiffTreeElements(main, candidate *TreeElement, keyLookup KeyNamesLookupFunc) (*sdcpb.UpdatesDiff, error) {
+	mainUpdates, err := main.ToUpdates(keyLookup)
+	if err != nil {
+		return nil, err
+	}
+	candidateUpdates, err := candidate.ToUpdates(keyLookup)
+	if err != nil {
+		return nil, err
+	}
+	return sdcpb.DiffUpdates(mainUpdates, candidateUpdates), nil
+}
+
+// FromUpdates builds an Intent from the flat list of updates. List keys are stored as nested levels below
+// the list element, one level per key in alphabetical order of the key names. The keyLookup is used to
+// verify that every list element carries all its keys, which is required to restore the paths in ToUpdates.
+// If a path is present multiple times, the last update wins.
+func FromUpdates(intentName string, priority int32, updates []*sdcpb.Update, keyLookup KeyNamesLookupFunc) (*Intent, error) {
+	if keyLookup == nil {
+		return nil, errNoKeyLookup
+	}
+	root := &TreeElement{}
+	idx := treeIndex{}
+	for _, u := range updates {
+		if err := idx.addUpdate(root, u, keyLookup); err != nil {
+			return nil, fmt.Errorf("update %s: %w", u.GetPath().ToXPath(false), err)
+		}
+	}
+	return &Intent{
+		IntentName: intentName,
+		Priority:   priority,
+		Root:       root,
+	}, nil
+}
+
+// treeIndex holds the children of the TreeElements by name while a tree is built.
+type treeIndex map[*TreeElement]map[string]*TreeElement
+
+func (idx treeIndex) addUpdate(te *TreeElement, u *sdcpb.Update, keyLookup KeyNamesLookupFunc) error {
+	n := te
+	current := &sdcpb.Path{IsRootBased: true}
+	for _, pe := range u.GetPath().GetElem() {
+		keyNames, err := keyLookup(current.CopyPathAddElem(&sdcpb.PathElem{Name: pe.GetName()}))
+		if err != nil {
+			return err
+		}
+		if len(keyNames) != len(pe.GetKey()) {
+			return fmt.Errorf("element %s requires keys %v", pe.GetName(), keyNames)
+		}
+		for _, k := range keyNames {
+			if _, exists := pe.GetKey()[k]; !exists {
+				return fmt.Errorf("element %s requires keys %v", pe.GetName(), keyNames)
+			}
+		}
+		current = current.CopyPathAddElem(pe)
+		for level := range pe.PathElemNames() {
+			n = idx.child(n, level)
+		}
+	}
+	leafVariant, err := proto.MarshalOptions{Deterministic: true}.Marshal(u.GetValue())
+	if err != nil {
+		return err
+	}
+	n.LeafVariant = leafVariant
+	return nil
+}
+
+// child returns the child of te with the given name, creating it if it does not exist yet.
+func (idx treeIndex) child(te *TreeElement, name string) *TreeElement {
+	children, ok := idx[te]
+	if !ok {
+		children = map[string]*TreeElement{}
+		idx[te] = children
+	}
+	if c, exists := children[name]; exists {
+		return c
+	}
+	c := &TreeElement{Name: name}
+	te.Childs = append(te.Childs, c)
+	children[name] = c
+	return c
+}
+
+// ToUpdates converts the tree of the Intent into a flat list of updates, see TreeElement.ToUpdates.
+// The tree only holds the key values of list entries as nested levels, the keyLookup provides the key names
+// and tells list elements from containers. It is required, a nil keyLookup returns an error.
+func (x *Intent) ToUpdates(keyLookup KeyNamesLookupFunc) ([]*sdcpb.Update, error) {
+	return x.GetRoot().ToUpdates(keyLookup)
+}
+
+// FromTransactionIntent builds an Intent from the TransactionIntent, including its explicit deletes,
+// the non-revertive and the orphan flag.
+func FromTransactionIntent(ti *sdcpb.TransactionIntent, keyLookup KeyNamesLookupFunc) (*Intent, error) {
+	intent, err := FromUpdates(ti.GetIntent(), ti.GetPriority(), ti.GetUpdate(), keyLookup)
+	if err != nil {
+		return nil, err
+	}
+	intent.ExplicitDeletes = ti.GetDeletes()
+	intent.NonRevertive = ti.GetNonRevertive()
+	intent.Orphan = ti.GetOrphan()
+	return intent, nil
+}
+
+// ToTransactionIntent converts the Intent into a TransactionIntent, the inverse of FromTransactionIntent.
+func (x *Intent) ToTransactionIntent(keyLookup KeyNamesLookupFunc) (*sdcpb.TransactionIntent, error) {
+	updates, err := x.ToUpdates(keyLookup)
+	if err != nil {
+		return nil, err
+	}
+	return &sdcpb.TransactionIntent{
+		Intent:       x.GetIntentName(),
+		Priority:     x.GetPriority(),
+		Update:       updates,
+		Deletes:      x.GetExplicitDeletes(),
+		NonRevertive: x.GetNonRevertive(),
+		Orphan:       x.GetOrphan(),
+	}, nil
+}
@@ -0,0 +1,237 @@
+package tree_persist
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func testKeyLookup(lists map[string][]string) KeyNamesLookupFunc {
+	return func(p *sdcpb.Path) ([]string, error) {
+		return lists[p.ToXPath(true)], nil
+	}
+}
+
+func mustLeafVariant(t *testing.T, tv *sdcpb.TypedValue) []byte {
+	t.Helper()
+	b, err := proto.Marshal(tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// testInterfaces returns a tree holding the descriptions of the interfaces, the interface name is the key level.
+func testInterfaces(t *testing.T, descriptions map[string]string) *TreeElement {
+	list := &TreeElement{Name: "interface"}
+	for name, description := range descriptions {
+		list.Childs = append(list.Childs, &TreeElement{Name: name, Childs: []*TreeElement{
+			{Name: "description", LeafVariant: mustLeafVariant(t, &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: description}})},
+		}})
+	}
+	return &TreeElement{Childs: []*TreeElement{list}}
+}
+
+func TestDiffTreeElements(t *testing.T) {
+	keyLookup := testKeyLookup(map[string][]string{"/interface": {"name"}})
+	main := testInterfaces(t, map[string]string{"eth0": "uplink", "eth1": "unused", "eth2": "same"})
+	candidate := testInterfaces(t, map[string]string{"eth0": "core", "eth2": "same", "eth3": "new"})
+
+	diff, err := DiffTreeElements(main, candidate, keyLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].GetPath().ToXPath(false) != "/interface[name=eth0]/description" ||
+		diff.Changed[0].GetMainValue().GetStringVal() != "uplink" || diff.Changed[0].GetCandidateValue().GetStringVal() != "core" {
+		t.Errorf("Changed = %v, want the eth0 description changed from uplink to core", diff.Changed)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].GetPath().ToXPath(false) != "/interface[name=eth3]/description" {
+		t.Errorf("Added = %v, want the eth3 description", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ToXPath(false) != "/interface[name=eth1]/description" {
+		t.Errorf("Removed = %v, want the eth1 description", diff.Removed)
+	}
+
+	diff, err = DiffTreeElements(main, main, keyLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.IsEmpty() {
+		t.Errorf("DiffTreeElements() of identical trees = %v, want no differences", diff)
+	}
+
+	// errors of the key lookup and invalid leaf values are returned
+	lookupErr := errors.New("lookup failed")
+	if _, err := DiffTreeElements(main, candidate, func(*sdcpb.Path) ([]string, error) { return nil, lookupErr }); !errors.Is(err, lookupErr) {
+		t.Errorf("DiffTreeElements() error = %v, want %v", err, lookupErr)
+	}
+	invalid := &TreeElement{Childs: []*TreeElement{{Name: "mtu", LeafVariant: []byte{0xff}}}}
+	if _, err := DiffTreeElements(main, invalid, keyLookup); err == nil {
+		t.Errorf("DiffTreeElements() wanted error for an invalid leaf value")
+	}
+}
+
+func mustParsePath(t *testing.T, s string) *sdcpb.Path {
+	t.Helper()
+	p, err := sdcpb.ParsePath(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestTransactionIntentRoundTrip(t *testing.T) {
+	keyLookup := testKeyLookup(map[string][]string{
+		"/interface":        {"name"},
+		"/network-instance": {"name"},
+		"/network-instance/protocols/bgp/neighbor": {"peer-address", "afi"},
+	})
+	ti := &sdcpb.TransactionIntent{
+		Intent:   "intent1",
+		Priority: 10,
+		Update: []*sdcpb.Update{
+			{
+				Path:  mustParsePath(t, "/interface[name=ethernet-1/1]/description"),
+				Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "uplink"}},
+			},
+			{
+				Path:  mustParsePath(t, "/interface[name=ethernet-1/1]/mtu"),
+				Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_UintVal{UintVal: 9000}},
+			},
+			{
+				Path:  mustParsePath(t, "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1][afi=ipv4]/peer-as"),
+				Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_UintVal{UintVal: 65000}},
+			},
+		},
+		Deletes: []*sdcpb.Path{
+			mustParsePath(t, "/interface[name=ethernet-1/2]"),
+		},
+		NonRevertive: true,
+		Orphan:       true,
+	}
+
+	intent, err := FromTransactionIntent(ti, keyLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := intent.ToTransactionIntent(keyLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, ti) {
+		t.Errorf("ToTransactionIntent() = %v, want %v", got, ti)
+	}
+}
+
+func TestFromUpdates_MissingKeys(t *testing.T) {
+	keyLookup := testKeyLookup(map[string][]string{"/interface": {"name"}})
+	_, err := FromUpdates("intent1", 10, []*sdcpb.Update{
+		{
+			Path:  mustParsePath(t, "/interface/description"),
+			Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "uplink"}},
+		},
+	}, keyLookup)
+	if err == nil {
+		t.Errorf("expected error for a list element without keys")
+	}
+	if _, err := FromUpdates("intent1", 10, nil, nil); !errors.Is(err, errNoKeyLookup) {
+		t.Errorf("FromUpdates() error = %v, want %v", err, errNoKeyLookup)
+	}
+}
+
+func TestTreeElement_ToUpdates(t *testing.T) {
+	keyLookup := testKeyLookup(map[string][]string{
+		"/interface": {"name"},
+		"/neighbor":  {"peer-address", "afi"},
+	})
+	asTv := &sdcpb.TypedValue{Value: &sdcpb.TypedValue_UintVal{UintVal: 65000}}
+	mtuTv := &sdcpb.TypedValue{Value: &sdcpb.TypedValue_UintVal{UintVal: 9000}}
+	root := &TreeElement{Childs: []*TreeElement{
+		{Name: "interface", Childs: []*TreeElement{
+			{Name: "ethernet-1/1", Childs: []*TreeElement{
+				{Name: "mtu", LeafVariant: mustLeafVariant(t, mtuTv)},
+			}},
+		}},
+		// the key levels are ordered alphabetically by the key names, afi before peer-address
+		{Name: "neighbor", Childs: []*TreeElement{
+			{Name: "ipv4", Childs: []*TreeElement{
+				{Name: "10.0.0.1", Childs: []*TreeElement{
+					{Name: "peer-as", LeafVariant: mustLeafVariant(t, asTv)},
+				}},
+			}},
+		}},
+	}}
+
+	got, err := root.ToUpdates(keyLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*sdcpb.Update{
+		{Path: mustParsePath(t, "/interface[name=ethernet-1/1]/mtu"), Value: mtuTv},
+		{Path: mustParsePath(t, "/neighbor[afi=ipv4][peer-address=10.0.0.1]/peer-as"), Value: asTv},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ToUpdates() returned %d updates, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !proto.Equal(got[i], want[i]) {
+			t.Errorf("ToUpdates()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// errors of the key lookup and invalid leaf values are returned
+	lookupErr := errors.New("lookup failed")
+	if _, err := root.ToUpdates(func(*sdcpb.Path) ([]string, error) { return nil, lookupErr }); !errors.Is(err, lookupErr) {
+		t.Errorf("ToUpdates() error = %v, want %v", err, lookupErr)
+	}
+	if _, err := root.ToUpdates(nil); !errors.Is(err, errNoKeyLookup) {
+		t.Errorf("ToUpdates(nil) error = %v, want %v", err, errNoKeyLookup)
+	}
+	invalid := &TreeElement{Childs: []*TreeElement{{Name: "mtu", LeafVariant: []byte{0xff}}}}
+	if _, err := invalid.ToUpdates(keyLookup); err == nil {
+		t.Errorf("ToUpdates() wanted error for an invalid leaf value")
+	}
+}
+
+func TestKeyNamesFromSchema(t *testing.T) {
+	lookupErr := errors.New("unknown path")
+	keyLookup := KeyNamesFromSchema(func(p *sdcpb.Path) (*sdcpb.SchemaElem, error) {
+		switch p.ToXPath(true) {
+		case "/neighbor":
+			return &sdcpb.SchemaElem{Schema: &sdcpb.SchemaElem_Container{Container: &sdcpb.ContainerSchema{
+				Name: "neighbor",
+				Keys: []*sdcpb.LeafSchema{{Name: "peer-address"}, {Name: "afi"}},
+			}}}, nil
+		case "/system":
+			return &sdcpb.SchemaElem{Schema: &sdcpb.SchemaElem_Container{Container: &sdcpb.ContainerSchema{Name: "system"}}}, nil
+		case "/system/hostname":
+			return &sdcpb.SchemaElem{Schema: &sdcpb.SchemaElem_Field{Field: &sdcpb.LeafSchema{Name: "hostname"}}}, nil
+		}
+		return nil, lookupErr
+	})
+
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr error
+	}{
+		{path: "/neighbor", want: []string{"peer-address", "afi"}},
+		{path: "/system", want: nil},
+		{path: "/system/hostname", want: nil},
+		{path: "/unknown", wantErr: lookupErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := keyLookup(mustParsePath(t, tt.path))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("key names = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Nokia
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"math/big"
+	"strings"
+)
+
+// DecimalRnges represents a collection of decimal ranges, the decimal counterpart of Rnges
+type DecimalRnges struct {
+	rnges []DecimalRng
+}
+
+// DecimalRng represents a single decimal range, the boundaries are compared exactly
+type DecimalRng struct {
+	min *big.Rat
+	max *big.Rat
+}
+
+func NewDecimalRnges() *DecimalRnges {
+	return &DecimalRnges{rnges: make([]DecimalRng, 0)}
+}
+
+func (r *DecimalRng) IsInRange(value *big.Rat) bool {
+	return r.min.Cmp(value) <= 0 && value.Cmp(r.max) <= 0
+}
+
+func (r *DecimalRng) String() string {
+	return decimalRatString(r.min) + ".." + decimalRatString(r.max)
+}
+
+func (r *DecimalRnges) IsWithinAnyRange(val *big.Rat) bool {
+	// if no ranges defined, every value is valid
+	if len(r.rnges) == 0 {
+		return true
+	}
+	for _, rng := range r.rnges {
+		if rng.IsInRange(val) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DecimalRnges) AddRange(min, max *big.Rat) {
+	r.rnges = append(r.rnges, DecimalRng{
+		min: min,
+		max: max,
+	})
+}
+
+func (r *DecimalRnges) String() string {
+	sb := &strings.Builder{}
+	sep := ""
+	sb.WriteString("[ ")
+	for _, dr := range r.rnges {
+		sb.WriteString(sep)
+		sb.WriteString(dr.String())
+		sep = ", "
+	}
+	sb.WriteString(" ]")
+	return sb.String()
+}
+
+// decimalRatString formats the rat with as many fraction digits as needed to represent it exactly,
+// capped at the 18 fraction digits decimal64 supports.
+func decimalRatString(r *big.Rat) string {
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	prec := 0
+	for ; prec < 18 && !scaled.IsInt(); prec++ {
+		scaled.Mul(scaled, ten)
+	}
+	return r.FloatString(prec)
+}